@@ -66,6 +66,8 @@ func (l *loader) loadProvider() *di.Provider {
 			{Import: "github.com/livebud/bud/package/gomod", Type: "*Module"},
 			{Import: "github.com/livebud/bud/package/budhttp", Type: "Client"},
 			{Import: "github.com/livebud/bud/package/remotefs", Type: "*Client"},
+			{Import: "github.com/livebud/bud/package/router", Type: "*Router"},
+			{Import: "github.com/livebud/bud/package/session", Type: "Store"},
 			{Import: "context", Type: "Context"},
 		},
 		Results: []di.Dependency{
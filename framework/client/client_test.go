@@ -0,0 +1,107 @@
+package client_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/package/testdir"
+)
+
+const usersController = `
+	package users
+	import "context"
+	type Controller struct {}
+	type User struct {
+		ID int ` + "`" + `json:"id"` + "`" + `
+		Email string ` + "`" + `json:"email"` + "`" + `
+	}
+	func (c *Controller) Index(ctx context.Context, page int) ([]*User, error) {
+		return []*User{}, nil
+	}
+	func (c *Controller) Create(in *User) (*User, error) {
+		return in, nil
+	}
+	func (c *Controller) Show(id int) (*User, error) {
+		return &User{ID: id}, nil
+	}
+	func (c *Controller) Edit(id int) (user *User, count int, err error) {
+		return &User{ID: id}, 1, nil
+	}
+	func (c *Controller) Delete() error {
+		return nil
+	}
+`
+
+func TestGenerateClient(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = usersController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	data, err := fs.ReadFile(td, "bud/client/client.go")
+	is.NoErr(err)
+	code := string(data)
+	is.In(code, `func New(baseURL string, options ...clientrt.Option) *Client {`)
+	is.In(code, `func (c *Client) UsersIndex(ctx context.Context, page int) ([]*users.User, error) {`)
+	is.In(code, `err := c.rt.Do(ctx, "GET", "/users", map[string]interface{}{"page": page}, &out)`)
+	is.In(code, `func (c *Client) UsersCreate(ctx context.Context, in *users.User) (*users.User, error) {`)
+	is.In(code, `err := c.rt.Do(ctx, "POST", "/users", in, &out)`)
+	is.In(code, `func (c *Client) UsersShow(ctx context.Context, id int) (*users.User, error) {`)
+	is.In(code, `func (c *Client) UsersEdit(ctx context.Context, id int) (*users.User, int, error) {`)
+	is.In(code, "User  *users.User `json:\"user\"`")
+	is.In(code, `return out.User, out.Count, err`)
+	// Slots that aren't inputs are still filled in
+	is.In(code, `func (c *Client) UsersDelete(ctx context.Context, id string) error {`)
+	is.In(code, `return c.rt.Do(ctx, "DELETE", "/users/:id", map[string]interface{}{"id": id}, nil)`)
+	// The generated client compiles
+	cmd := exec.CommandContext(ctx, "go", "vet", "-mod=mod", "./bud/client")
+	cmd.Dir = td.Directory()
+	cmd.Stderr = os.Stderr
+	is.NoErr(cmd.Run())
+}
+
+func TestNoControllers(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.NoErr(td.NotExists("bud/client"))
+}
+
+func TestNoClientActions(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+	package controller
+	import "net/http"
+	type Controller struct {}
+	func (c *Controller) Index(w http.ResponseWriter, r *http.Request) {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	data, err := fs.ReadFile(td, "bud/client/client.go")
+	is.NoErr(err)
+	is.NotIn(string(data), `"context"`)
+	// The generated client compiles
+	cmd := exec.CommandContext(ctx, "go", "vet", "-mod=mod", "./bud/client")
+	cmd.Dir = td.Directory()
+	cmd.Stderr = os.Stderr
+	is.NoErr(cmd.Run())
+}
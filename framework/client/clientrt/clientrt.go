@@ -0,0 +1,477 @@
+package clientrt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/framework/controller/controllerrt/response"
+	"github.com/livebud/bud/package/router/lex"
+)
+
+type Option = func(o *option)
+
+type option struct {
+	client *http.Client
+	header http.Header
+}
+
+// WithHTTPClient sets the HTTP client used to make requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *option) {
+		o.client = client
+	}
+}
+
+// WithHeader adds a header to every request, e.g. Authorization
+func WithHeader(key, value string) Option {
+	return func(o *option) {
+		o.header.Add(key, value)
+	}
+}
+
+// New client for the app running at baseURL
+func New(baseURL string, options ...Option) *Client {
+	opt := &option{
+		client: http.DefaultClient,
+		header: http.Header{},
+	}
+	for _, option := range options {
+		option(opt)
+	}
+	return &Client{strings.TrimSuffix(baseURL, "/"), opt.client, opt.header}
+}
+
+// Client calls a bud app's actions over JSON
+type Client struct {
+	baseURL string
+	client  *http.Client
+	header  http.Header
+}
+
+// File to upload to an action that accepts an *upload.File
+type File struct {
+	Filename    string
+	ContentType string
+	Body        io.Reader
+}
+
+// Do calls the action at route, substituting the route's slots with the
+// values in the input. The remaining values are sent in the query string for
+// GET and DELETE requests and in the body otherwise. If out is not nil, the
+// JSON response is decoded into it.
+func (c *Client) Do(ctx context.Context, method, route string, in, out interface{}) error {
+	values, files, err := flatten(in)
+	if err != nil {
+		return fmt.Errorf("clientrt: unable to encode the input for %s %s. %w", method, route, err)
+	}
+	tags := bindTags(in, values)
+	urlPath, err := fill(route, values)
+	if err != nil {
+		return fmt.Errorf("clientrt: unable to fill in the route %q. %w", route, err)
+	}
+	var body io.Reader
+	contentType := ""
+	switch {
+	case len(files) > 0:
+		buf := new(bytes.Buffer)
+		if contentType, err = writeMultipart(buf, values, files); err != nil {
+			return fmt.Errorf("clientrt: unable to encode the files for %s %s. %w", method, route, err)
+		}
+		body = buf
+	case method == http.MethodGet || method == http.MethodDelete:
+		if query := encodeQuery(values); query != "" {
+			urlPath += "?" + query
+		}
+	case len(values) > 0:
+		data, err := json.Marshal(values)
+		if err != nil {
+			return fmt.Errorf("clientrt: unable to encode the body for %s %s. %w", method, route, err)
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+urlPath, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	for key, values := range tags.header {
+		req.Header[key] = values
+	}
+	for _, cookie := range tags.cookies {
+		req.AddCookie(cookie)
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return decodeError(res)
+	}
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("clientrt: unable to decode the response for %s %s. %w", method, route, err)
+	}
+	return nil
+}
+
+// Merge the struct input with extra values, like route slots that aren't
+// fields of the struct
+func Merge(in interface{}, extra map[string]interface{}) interface{} {
+	return &merged{in, extra}
+}
+
+type merged struct {
+	in    interface{}
+	extra map[string]interface{}
+}
+
+// flatten the input into JSON values by key, separating out the files
+func flatten(in interface{}) (map[string]json.RawMessage, map[string]*File, error) {
+	values := map[string]json.RawMessage{}
+	files := map[string]*File{}
+	if in == nil {
+		return values, files, nil
+	}
+	if m, ok := in.(*merged); ok {
+		values, files, err := flatten(m.in)
+		if err != nil {
+			return nil, nil, err
+		}
+		extra, extraFiles, err := flatten(m.extra)
+		if err != nil {
+			return nil, nil, err
+		}
+		for key, value := range extra {
+			values[key] = value
+		}
+		for key, file := range extraFiles {
+			files[key] = file
+		}
+		return values, files, nil
+	}
+	if params, ok := in.(map[string]interface{}); ok {
+		rest := make(map[string]interface{}, len(params))
+		for key, value := range params {
+			if file, ok := value.(*File); ok {
+				if file != nil {
+					files[key] = file
+				}
+				continue
+			}
+			rest[key] = value
+		}
+		in = rest
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, nil, err
+	}
+	// Nil inputs unmarshal into a nil map
+	if values == nil {
+		values = map[string]json.RawMessage{}
+	}
+	return values, files, nil
+}
+
+type tags struct {
+	header  http.Header
+	cookies []*http.Cookie
+}
+
+// bindTags moves the struct fields tagged with `path`, `header` or `cookie`
+// out of the values. Path fields are renamed to their slot, while header and
+// cookie fields are returned to be sent with the request.
+func bindTags(in interface{}, values map[string]json.RawMessage) *tags {
+	t := &tags{header: http.Header{}}
+	if m, ok := in.(*merged); ok {
+		in = m.in
+	}
+	rv := reflect.ValueOf(in)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return t
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return t
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if key == "" {
+			key = field.Name
+		}
+		for _, source := range []string{"path", "header", "cookie"} {
+			name := field.Tag.Get(source)
+			if name == "" {
+				continue
+			}
+			raw, ok := values[key]
+			delete(values, key)
+			if !ok || string(raw) == "null" {
+				break
+			}
+			switch source {
+			case "path":
+				values[name] = raw
+			case "header":
+				var list []json.RawMessage
+				if err := json.Unmarshal(raw, &list); err != nil {
+					list = []json.RawMessage{raw}
+				}
+				for _, item := range list {
+					t.header.Add(name, toString(item))
+				}
+			case "cookie":
+				t.cookies = append(t.cookies, &http.Cookie{Name: name, Value: toString(raw)})
+			}
+			break
+		}
+	}
+	return t
+}
+
+// fill in the route's slots, removing them from values
+func fill(route string, values map[string]json.RawMessage) (string, error) {
+	out := new(strings.Builder)
+	var err error
+	lexer := lex.New(route)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.ErrorToken:
+			return "", fmt.Errorf("%s", token.Value)
+		case lex.EndToken:
+			return out.String(), err
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken:
+			key := lookup(values, token.Slot())
+			raw, ok := values[key]
+			delete(values, key)
+			value := ""
+			if ok {
+				value = toString(raw)
+			}
+			if value == "" {
+				// Keep lexing until the end, the lexer doesn't stop early
+				if token.Type == lex.SlotToken && err == nil {
+					err = fmt.Errorf("missing a value for the %q slot", key)
+				}
+				// Drop the slash before a missing optional slot
+				path := strings.TrimSuffix(out.String(), "/")
+				out.Reset()
+				out.WriteString(path)
+				continue
+			}
+			if token.Type == lex.StarToken {
+				segments := strings.Split(value, "/")
+				for i, segment := range segments {
+					segments[i] = url.PathEscape(segment)
+				}
+				out.WriteString(strings.Join(segments, "/"))
+				continue
+			}
+			out.WriteString(url.PathEscape(value))
+		default:
+			out.WriteString(token.Value)
+		}
+	}
+}
+
+// lookup the key for a slot. Struct fields without a json tag are matched
+// regardless of case, like the request decoder does.
+func lookup(values map[string]json.RawMessage, slot string) string {
+	if _, ok := values[slot]; ok {
+		return slot
+	}
+	for key := range values {
+		if strings.EqualFold(key, slot) {
+			return key
+		}
+	}
+	return slot
+}
+
+// encodeQuery encodes the values as a query string. Lists are repeated.
+func encodeQuery(values map[string]json.RawMessage) string {
+	query := url.Values{}
+	for key, raw := range values {
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err == nil {
+			for _, item := range list {
+				query.Add(key, toString(item))
+			}
+			continue
+		}
+		if string(raw) == "null" {
+			continue
+		}
+		query.Set(key, toString(raw))
+	}
+	return query.Encode()
+}
+
+func writeMultipart(w io.Writer, values map[string]json.RawMessage, files map[string]*File) (string, error) {
+	writer := multipart.NewWriter(w)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if string(values[key]) == "null" {
+			continue
+		}
+		if err := writer.WriteField(key, toString(values[key])); err != nil {
+			return "", err
+		}
+	}
+	keys = keys[:0]
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		file := files[key]
+		header := make(map[string][]string)
+		header["Content-Disposition"] = []string{mime.FormatMediaType("form-data", map[string]string{
+			"name":     key,
+			"filename": file.Filename,
+		})}
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header["Content-Type"] = []string{contentType}
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.Copy(part, file.Body); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return writer.FormDataContentType(), nil
+}
+
+// toString converts a JSON value into its string form, unquoting strings
+func toString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// Tuple decodes a JSON array into each of the values in order. It's used for
+// actions that return more than one unnamed result.
+func Tuple(values ...interface{}) json.Unmarshaler {
+	t := tuple(values)
+	return &t
+}
+
+type tuple []interface{}
+
+func (t *tuple) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	for i, value := range *t {
+		if i >= len(items) {
+			break
+		}
+		if err := json.Unmarshal(items[i], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Error is returned when the app responds with a 4xx or 5xx status. It
+// matches the response package's errors with the same status code, so
+// errors.Is(err, response.ErrNotFound) reports whether the app responded with
+// a 404.
+type Error struct {
+	Status  int
+	Message string
+	Fields  map[string][]string // Fields that failed validation
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("clientrt: %d %s", e.Status, e.Message)
+}
+
+// StatusCode returns the response status, so the error keeps its status code
+// if it's returned from another action
+func (e *Error) StatusCode() int {
+	return e.Status
+}
+
+func (e *Error) Is(target error) bool {
+	return target != nil && response.StatusCode(target) == e.Status
+}
+
+// Unwrap the validation error, if any
+func (e *Error) Unwrap() error {
+	if e.Fields == nil {
+		return nil
+	}
+	return &request.ValidationError{Fields: e.Fields}
+}
+
+func decodeError(res *http.Response) error {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	// Errors are application/problem+json, but fall back to the plain text body
+	// for errors that don't come from an action
+	body := struct {
+		Detail string              `json:"detail"`
+		Fields map[string][]string `json:"fields"`
+	}{}
+	e := &Error{Status: res.StatusCode}
+	if err := json.Unmarshal(data, &body); err != nil {
+		e.Message = strings.TrimSpace(string(data))
+	} else {
+		e.Message = body.Detail
+	}
+	e.Fields = body.Fields
+	if e.Message == "" {
+		e.Message = strings.ToLower(http.StatusText(res.StatusCode))
+	}
+	return e
+}
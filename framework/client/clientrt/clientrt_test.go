@@ -0,0 +1,213 @@
+package clientrt_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/framework/client/clientrt"
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/framework/controller/controllerrt/response"
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/upload"
+)
+
+type Post struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func TestGetQuery(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "GET")
+		is.Equal(r.URL.Path, "/users/10/posts")
+		is.Equal(r.URL.RawQuery, "page=2&tags=a&tags=b+c")
+		is.Equal(r.Header.Get("Accept"), "application/json")
+		is.Equal(r.Header.Get("Authorization"), "Bearer token")
+		response.JSON([]*Post{{ID: 1, Title: "a"}}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL+"/", clientrt.WithHeader("Authorization", "Bearer token"))
+	var posts []*Post
+	err := client.Do(context.Background(), "GET", "/users/:user_id/posts", map[string]interface{}{
+		"user_id": 10,
+		"page":    2,
+		"tags":    []string{"a", "b c"},
+	}, &posts)
+	is.NoErr(err)
+	is.Equal(len(posts), 1)
+	is.Equal(posts[0].Title, "a")
+}
+
+func TestPostJSON(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "POST")
+		is.Equal(r.URL.EscapedPath(), "/users/a%2Fb/posts")
+		is.Equal(r.Header.Get("Content-Type"), "application/json")
+		body, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		is.Equal(string(body), `{"title":"hello"}`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"title":"hello"}`))
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	var post *Post
+	err := client.Do(context.Background(), "POST", "/users/:user_id/posts", map[string]interface{}{
+		"user_id": "a/b",
+		"title":   "hello",
+	}, &post)
+	is.NoErr(err)
+	is.Equal(post.ID, 1)
+}
+
+func TestMergeStruct(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.Method, "PATCH")
+		is.Equal(r.URL.Path, "/users/3/posts/1")
+		body, err := io.ReadAll(r.Body)
+		is.NoErr(err)
+		is.Equal(string(body), `{"title":"hi"}`)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	in := &Post{ID: 1, Title: "hi"}
+	err := client.Do(context.Background(), "PATCH", "/users/:user_id/posts/:id", clientrt.Merge(in, map[string]interface{}{"user_id": "3"}), nil)
+	is.NoErr(err)
+}
+
+func TestTags(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/posts/5")
+		is.Equal(r.URL.RawQuery, "title=hi")
+		is.Equal(r.Header.Get("X-Api-Key"), "secret")
+		cookie, err := r.Cookie("session")
+		is.NoErr(err)
+		is.Equal(cookie.Value, "abc")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	in := &struct {
+		ID      int    `json:"id" path:"post_id"`
+		Title   string `json:"title"`
+		Key     string `json:"key" header:"X-Api-Key"`
+		Session string `cookie:"session"`
+	}{5, "hi", "secret", "abc"}
+	err := client.Do(context.Background(), "GET", "/posts/:post_id", in, nil)
+	is.NoErr(err)
+}
+
+func TestMissingSlot(t *testing.T) {
+	is := is.New(t)
+	client := clientrt.New("http://localhost")
+	err := client.Do(context.Background(), "GET", "/users/:id", nil, nil)
+	is.True(err != nil)
+	is.In(err.Error(), `missing a value for the "id" slot`)
+}
+
+func TestOptionalSlot(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/docs")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	err := client.Do(context.Background(), "GET", "/docs/:page?", nil, nil)
+	is.NoErr(err)
+}
+
+func TestUpload(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := struct {
+			Name   string       `json:"name"`
+			Avatar *upload.File `json:"avatar"`
+		}{}
+		is.NoErr(request.Unmarshal(r, &in))
+		is.Equal(in.Name, "Alice")
+		is.Equal(in.Avatar.Filename, "avatar.png")
+		is.Equal(in.Avatar.ContentType, "image/png")
+		file, err := in.Avatar.Open()
+		is.NoErr(err)
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		is.NoErr(err)
+		is.Equal(string(data), "png")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	err := client.Do(context.Background(), "POST", "/users", map[string]interface{}{
+		"name": "Alice",
+		"avatar": &clientrt.File{
+			Filename:    "avatar.png",
+			ContentType: "image/png",
+			Body:        strings.NewReader("png"),
+		},
+	}, nil)
+	is.NoErr(err)
+}
+
+func TestTuple(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.JSON([]interface{}{&Post{ID: 1}, 5}).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	var post *Post
+	var count int
+	err := client.Do(context.Background(), "GET", "/", nil, clientrt.Tuple(&post, &count))
+	is.NoErr(err)
+	is.Equal(post.ID, 1)
+	is.Equal(count, 5)
+}
+
+func TestNotFound(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(response.Problem(response.ErrNotFound))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	err := client.Do(context.Background(), "GET", "/users/:id", map[string]interface{}{"id": 2}, nil)
+	is.True(err != nil)
+	is.True(errors.Is(err, response.ErrNotFound))
+	is.True(!errors.Is(err, response.ErrForbidden))
+	is.Equal(response.StatusCode(err), 404)
+	is.Equal(err.Error(), "clientrt: 404 not found")
+}
+
+func TestValidation(t *testing.T) {
+	is := is.New(t)
+	verr := &request.ValidationError{Fields: map[string][]string{"email": {"is required"}}}
+	server := httptest.NewServer(response.Problem(verr))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	err := client.Do(context.Background(), "POST", "/users", nil, nil)
+	is.True(err != nil)
+	is.Equal(response.StatusCode(err), 422)
+	is.Equal(response.Fields(err)["email"], []string{"is required"})
+}
+
+func TestServerError(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	client := clientrt.New(server.URL)
+	err := client.Do(context.Background(), "GET", "/", nil, nil)
+	is.True(err != nil)
+	is.Equal(response.StatusCode(err), 500)
+	is.Equal(err.Error(), "clientrt: 500 boom")
+}
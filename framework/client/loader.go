@@ -0,0 +1,269 @@
+package client
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/livebud/bud/framework/controller"
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/imports"
+	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/matthewmueller/gotext"
+)
+
+// Load the client state from the controllers
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser) (*State, error) {
+	state, err := controller.Load(fsys, injector, module, parser)
+	if err != nil {
+		return nil, err
+	}
+	loader := &loader{
+		imports: imports.New(),
+		parser:  parser,
+	}
+	return loader.Load(state)
+}
+
+type loader struct {
+	bail.Struct
+	imports *imports.Set
+	parser  *parser.Parser
+}
+
+func (l *loader) Load(controller *controller.State) (state *State, err error) {
+	defer l.Recover2(&err, "client: unable to load state")
+	state = new(State)
+	l.imports.AddNamed("clientrt", "github.com/livebud/bud/framework/client/clientrt")
+	state.Actions = l.loadController(controller.Controller)
+	// Every action is filtered out when they're all streams, sockets or handler
+	// functions
+	if len(state.Actions) > 0 {
+		l.imports.AddStd("context")
+	}
+	state.Imports = l.imports.List()
+	return state, nil
+}
+
+func (l *loader) loadController(controller *controller.Controller) (actions []*Action) {
+	if len(controller.Actions) > 0 {
+		pkg, err := l.parser.Parse(path.Join("controller", controller.Path))
+		if err != nil {
+			l.Bail(err)
+		}
+		stct := pkg.Struct("Controller")
+		if stct == nil {
+			l.Bail(fmt.Errorf("unable to find the controller in %q", pkg.Directory()))
+		}
+		for _, action := range controller.Actions {
+			// Handler functions write their own responses, streams are read as
+			// they're produced and sockets stay open
+			if action.HandlerFunc || action.Socket || action.Results.Stream() != "" {
+				continue
+			}
+			method := stct.Method(action.Name)
+			if method == nil {
+				l.Bail(fmt.Errorf("unable to find the %s action in %q", action.Name, pkg.Directory()))
+			}
+			actions = append(actions, l.loadAction(controller, action, method))
+		}
+	}
+	for _, subController := range controller.Controllers {
+		actions = append(actions, l.loadController(subController)...)
+	}
+	return actions
+}
+
+func (l *loader) loadAction(controller *controller.Controller, action *controller.Action, method *parser.Function) *Action {
+	slots := l.loadMissingSlots(action, method)
+	return &Action{
+		Name:    gotext.Pascal(controller.Name + " " + action.Name),
+		Method:  strings.ToUpper(action.Method),
+		Route:   action.Route,
+		Params:  append(l.loadSlotParams(slots), l.loadParams(action, method)...),
+		Input:   l.loadInput(action, slots),
+		Results: l.loadResults(method),
+	}
+}
+
+// loadMissingSlots returns the route's slots that aren't inputs of the action.
+// They still need to be filled in to call the action.
+func (l *loader) loadMissingSlots(action *controller.Action, method *parser.Function) (slots []string) {
+	inputs := map[string]bool{}
+	methodParams := method.Params()
+	for i, ap := range action.Params {
+		if ap.IsContext() {
+			continue
+		}
+		// Single struct input
+		if ap.Variable == "in" {
+			for _, name := range l.loadStructKeys(methodParams[i]) {
+				inputs[strings.ToLower(name)] = true
+			}
+			continue
+		}
+		inputs[strings.ToLower(ap.Snake)] = true
+	}
+	lexer := lex.New(action.Route)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.ErrorToken:
+			l.Bail(fmt.Errorf("unable to parse route. %s", token.Value))
+		case lex.EndToken:
+			return slots
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken:
+			slot := token.Slot()
+			if !inputs[strings.ToLower(slot)] {
+				slots = append(slots, slot)
+			}
+		}
+	}
+}
+
+// loadStructKeys loads the JSON keys of a struct input
+func (l *loader) loadStructKeys(param *parser.Param) (keys []string) {
+	def, err := param.Definition()
+	if err != nil {
+		l.Bail(err)
+	}
+	stct := def.Package().Struct(def.Name())
+	if stct == nil {
+		l.Bail(fmt.Errorf("unable to find struct for %s", param.Type()))
+	}
+	for _, field := range stct.PublicFields() {
+		tags, err := field.Tags()
+		if err != nil {
+			l.Bail(err)
+		}
+		key := field.Name()
+		if value := tags.Get("json"); value == "-" {
+			continue
+		} else if value != "" {
+			key = value
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (l *loader) loadSlotParams(slots []string) (params []*Param) {
+	for _, slot := range slots {
+		params = append(params, &Param{
+			Name: slotName(slot),
+			Type: "string",
+		})
+	}
+	return params
+}
+
+func slotName(slot string) string {
+	name := gotext.Camel(slot)
+	if reserved[name] {
+		return name + "In"
+	}
+	return name
+}
+
+// reserved names are used by the generated method
+var reserved = map[string]bool{
+	"c":   true,
+	"ctx": true,
+	"err": true,
+	"out": true,
+}
+
+func paramName(ap *controller.ActionParam) string {
+	if reserved[ap.Name] {
+		return ap.Name + "In"
+	}
+	return ap.Name
+}
+
+func (l *loader) loadParams(action *controller.Action, method *parser.Function) (params []*Param) {
+	methodParams := method.Params()
+	for i, ap := range action.Params {
+		if ap.IsContext() {
+			continue
+		}
+		param := &Param{Name: paramName(ap)}
+		if ap.Upload {
+			param.Type = "*clientrt.File"
+		} else {
+			param.Type = l.loadType(methodParams[i].Type(), methodParams[i])
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// loadInput loads the expression that's passed to the runtime
+func (l *loader) loadInput(action *controller.Action, slots []string) string {
+	var entries []string
+	for _, slot := range slots {
+		entries = append(entries, strconv.Quote(slot)+": "+slotName(slot))
+	}
+	for _, ap := range action.Params {
+		if ap.IsContext() {
+			continue
+		}
+		// Single struct input
+		if ap.Variable == "in" {
+			if len(entries) == 0 {
+				return paramName(ap)
+			}
+			return "clientrt.Merge(" + paramName(ap) + ", map[string]interface{}{" + strings.Join(entries, ", ") + "})"
+		}
+		entries = append(entries, strconv.Quote(ap.Snake)+": "+paramName(ap))
+	}
+	if len(entries) == 0 {
+		return "nil"
+	}
+	return "map[string]interface{}{" + strings.Join(entries, ", ") + "}"
+}
+
+func (l *loader) loadResults(method *parser.Function) (results []*Result) {
+	for i, result := range method.Results() {
+		if result.IsError() {
+			continue
+		}
+		name := result.Name()
+		if name == "" {
+			name = "out" + strconv.Itoa(i)
+		}
+		results = append(results, &Result{
+			Pascal:   gotext.Pascal(name),
+			Snake:    gotext.Snake(name),
+			Named:    result.Named(),
+			Type:     l.loadType(result.Type(), result),
+			Variable: "out" + strconv.Itoa(i),
+		})
+	}
+	return results
+}
+
+type definer interface {
+	Definition() (parser.Declaration, error)
+}
+
+// loadType qualifies the type so it can be used outside of the controller
+func (l *loader) loadType(dt parser.Type, definer definer) string {
+	dec, err := definer.Definition()
+	if err != nil {
+		l.Bail(fmt.Errorf("unable to find the definition for %s. %w", dt, err))
+	}
+	if dec.Kind() == parser.KindBuiltin {
+		return dt.String()
+	}
+	importPath, err := dec.Package().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	name := l.imports.Add(importPath)
+	return parser.Qualify(dt, name).String()
+}
@@ -0,0 +1,50 @@
+package client
+
+import "github.com/livebud/bud/package/imports"
+
+// State of the Go client
+type State struct {
+	Imports []*imports.Import
+	Actions []*Action
+}
+
+// Action is a client method that calls a controller action
+type Action struct {
+	Name    string // Name of the method
+	Method  string // HTTP method
+	Route   string // Route to the action
+	Params  []*Param
+	Input   string // Input expression passed to the runtime
+	Results []*Result
+}
+
+// Param of the client method
+type Param struct {
+	Name string
+	Type string
+}
+
+// Result of the client method, not including the error
+type Result struct {
+	Pascal   string
+	Snake    string
+	Named    bool
+	Type     string
+	Variable string
+}
+
+// Single is true if the action returns one result
+func (a *Action) Single() bool {
+	return len(a.Results) == 1
+}
+
+// Object is true if the action returns more than one result and they're all
+// named. They're encoded as a JSON object.
+func (a *Action) Object() bool {
+	for _, result := range a.Results {
+		if !result.Named {
+			return false
+		}
+	}
+	return len(a.Results) > 1
+}
@@ -3,6 +3,9 @@ package controller_test
 import (
 	"bytes"
 	"context"
+	"mime/multipart"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/livebud/bud/internal/versions"
 	"github.com/livebud/bud/package/testdir"
 	"github.com/matthewmueller/diff"
+	"golang.org/x/net/websocket"
 )
 
 func TestNoActions(t *testing.T) {
@@ -33,6 +37,7 @@ func TestNoActions(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 404 Not Found
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 		X-Content-Type-Options: nosniff
 	`))
 	is.NoErr(app.Close())
@@ -75,6 +80,7 @@ func TestIndexString(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "Root")
 	// JSON response
@@ -83,6 +89,7 @@ func TestIndexString(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"Root"
 	`))
@@ -92,6 +99,7 @@ func TestIndexString(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "About")
 	// JSON response
@@ -100,6 +108,7 @@ func TestIndexString(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"About"
 	`))
@@ -109,6 +118,7 @@ func TestIndexString(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "Comments")
 	// JSON response
@@ -117,6 +127,7 @@ func TestIndexString(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"Comments"
 	`))
@@ -149,7 +160,9 @@ func TestCreateRedirect(t *testing.T) {
 	is.NoErr(err)
 	defer app.Close()
 	// Redirect /
-	res, err := app.Post("/", nil)
+	req, err := app.PostRequest("/", nil)
+	is.NoErr(err)
+	res, err := app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 302 Found
@@ -157,7 +170,9 @@ func TestCreateRedirect(t *testing.T) {
 	`))
 	is.Equal(res.Body().Len(), 0)
 	// Redirect /users
-	res, err = app.Post("/users", nil)
+	req, err = app.PostRequest("/users", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -165,7 +180,9 @@ func TestCreateRedirect(t *testing.T) {
 	`))
 	is.Equal(res.Body().Len(), 0)
 	// Redirect /posts/10/comments
-	res, err = app.Post("/posts/10/comments", nil)
+	req, err = app.PostRequest("/posts/10/comments", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -309,9 +326,10 @@ func TestIndex500(t *testing.T) {
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 500 Internal Server Error
-		Content-Type: application/json
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
 
-		{"error":"unable to list posts"}
+		{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"unable to list posts"}
 	`))
 	is.NoErr(app.Close())
 }
@@ -456,6 +474,7 @@ func TestIndexStructs200(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[{"id":0,"title":"a"},{"id":1,"title":"b"}]
 	`))
@@ -507,9 +526,10 @@ func TestJSONCreate500(t *testing.T) {
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 500 Internal Server Error
-		Content-Type: application/json
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
 
-		{"error":"Not implemented yet"}
+		{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Not implemented yet"}
 	`))
 	is.NoErr(app.Close())
 }
@@ -544,6 +564,7 @@ func TestDependencyHoist(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		1
 	`))
@@ -581,6 +602,7 @@ func TestDependencyRequest(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `/`)
 	is.NoErr(app.Close())
@@ -616,6 +638,7 @@ func TestShareStruct(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"title":"a"}
 	`))
@@ -687,6 +710,7 @@ func TestJSONCreateNested(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":1,"title":"a"}
 	`))
@@ -695,6 +719,7 @@ func TestJSONCreateNested(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":2,"title":"b"}
 	`))
@@ -703,6 +728,7 @@ func TestJSONCreateNested(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"user_id":1,"id":3,"title":"c"}
 	`))
@@ -711,6 +737,7 @@ func TestJSONCreateNested(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":4,"title":"d"}
 	`))
@@ -740,9 +767,10 @@ func TestJSONDelete500(t *testing.T) {
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 500 Internal Server Error
-		Content-Type: application/json
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
 
-		{"error":"Not implemented yet"}
+		{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Not implemented yet"}
 	`))
 	is.NoErr(app.Close())
 }
@@ -773,6 +801,7 @@ func TestJSONDelete200(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"ID":1,"Title":"a"}
 	`))
@@ -805,6 +834,7 @@ func TestJSONMultipleActions(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"hello world"
 	`))
@@ -813,6 +843,7 @@ func TestJSONMultipleActions(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		10
 	`))
@@ -841,9 +872,10 @@ func TestJSONUpdate500(t *testing.T) {
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 500 Internal Server Error
-		Content-Type: application/json
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
 
-		{"error":"Not implemented yet"}
+		{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Not implemented yet"}
 	`))
 	is.NoErr(app.Close())
 }
@@ -874,6 +906,7 @@ func TestJSONUpdate200(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":1,"title":"a"}
 	`))
@@ -924,6 +957,7 @@ func TestNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[{"id":1,"name":"a","age":2},{"id":2,"name":"b","age":3}]
 	`))
@@ -937,6 +971,7 @@ func TestNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":3,"name":"matt","age":10}
 	`))
@@ -945,6 +980,7 @@ func TestNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"name":"d","age":5}
 	`))
@@ -1013,6 +1049,7 @@ func TestDeepNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[{"id":2,"post_id":1,"title":"a"},{"id":3,"post_id":1,"title":"b"}]
 	`))
@@ -1026,6 +1063,7 @@ func TestDeepNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":1,"post_id":1,"title":"1st"}
 	`))
@@ -1034,6 +1072,7 @@ func TestDeepNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":2,"post_id":1,"title":"a"}
 	`))
@@ -1047,6 +1086,7 @@ func TestDeepNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":1,"post_id":2,"title":"1st"}
 	`))
@@ -1055,6 +1095,7 @@ func TestDeepNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":1,"post_id":2}
 	`))
@@ -1063,6 +1104,7 @@ func TestDeepNestedResource(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":1,"post_id":2}
 	`))
@@ -1152,57 +1194,75 @@ func TestRedirectResource(t *testing.T) {
 	is.NoErr(err)
 	defer app.Close()
 	// Root
-	res, err := app.Post("/", nil)
+	req, err := app.PostRequest("/", nil)
+	is.NoErr(err)
+	res, err := app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /2
 	`))
-	res, err = app.Patch("/1", nil)
+	req, err = app.PatchRequest("/1", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /1
 	`))
-	res, err = app.Delete("/1", nil)
+	req, err = app.DeleteRequest("/1", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /
 	`))
 	// Posts
-	res, err = app.Post("/posts", nil)
+	req, err = app.PostRequest("/posts", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /posts/2
 	`))
-	res, err = app.Patch("/posts/1", nil)
+	req, err = app.PatchRequest("/posts/1", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /posts/1
 	`))
-	res, err = app.Delete("/posts/1", nil)
+	req, err = app.DeleteRequest("/posts/1", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /posts
 	`))
 	// Comments
-	res, err = app.Post("/posts/1/comments", nil)
+	req, err = app.PostRequest("/posts/1/comments", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /posts/1/comments/2
 	`))
-	res, err = app.Patch("/posts/1/comments/2", nil)
+	req, err = app.PatchRequest("/posts/1/comments/2", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
 		Location: /posts/1/comments/2
 	`))
-	res, err = app.Delete("/posts/1/comments/2", nil)
+	req, err = app.DeleteRequest("/posts/1/comments/2", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -1276,6 +1336,7 @@ func TestViewUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[{"id":1,"name":"a"},{"id":2,"name":"b"}]
 	`))
@@ -1285,6 +1346,7 @@ func TestViewUnnamed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	el, err := res.Query("#bud_target")
 	is.NoErr(err)
@@ -1298,16 +1360,16 @@ func TestViewUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":3,"name":"c"}
 	`))
 	res, err = app.Get("/new")
 	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 200 OK
-		Transfer-Encoding: chunked
-		Content-Type: text/html
-	`))
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/html")
+	// Form views store a CSRF token in the session
+	is.True(strings.HasPrefix(res.Header("Set-Cookie"), "session="))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
 	html, err = el.Html()
@@ -1320,6 +1382,7 @@ func TestViewUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"name":"s"}
 	`))
@@ -1329,6 +1392,7 @@ func TestViewUnnamed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
@@ -1342,16 +1406,16 @@ func TestViewUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"name":"e"}
 	`))
 	res, err = app.Get("/10/edit")
 	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 200 OK
-		Transfer-Encoding: chunked
-		Content-Type: text/html
-	`))
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/html")
+	// Form views store a CSRF token in the session
+	is.True(strings.HasPrefix(res.Header("Set-Cookie"), "session="))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
 	html, err = el.Html()
@@ -1425,6 +1489,7 @@ func TestViewNestedUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[{"id":1,"name":"a"},{"id":2,"name":"b"}]
 	`))
@@ -1434,6 +1499,7 @@ func TestViewNestedUnnamed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	el, err := res.Query("#bud_target")
 	is.NoErr(err)
@@ -1447,16 +1513,16 @@ func TestViewNestedUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":3,"name":"c"}
 	`))
 	res, err = app.Get("/users/new")
 	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 200 OK
-		Transfer-Encoding: chunked
-		Content-Type: text/html
-	`))
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/html")
+	// Form views store a CSRF token in the session
+	is.True(strings.HasPrefix(res.Header("Set-Cookie"), "session="))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
 	html, err = el.Html()
@@ -1469,6 +1535,7 @@ func TestViewNestedUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"name":"s"}
 	`))
@@ -1478,6 +1545,7 @@ func TestViewNestedUnnamed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
@@ -1491,16 +1559,16 @@ func TestViewNestedUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"name":"e"}
 	`))
 	res, err = app.Get("/users/10/edit")
 	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 200 OK
-		Transfer-Encoding: chunked
-		Content-Type: text/html
-	`))
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/html")
+	// Form views store a CSRF token in the session
+	is.True(strings.HasPrefix(res.Header("Set-Cookie"), "session="))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
 	html, err = el.Html()
@@ -1577,6 +1645,7 @@ func TestViewDeepUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[{"id":1,"name":"a","createdAt":"2021-08-04T14:56:00Z"},{"id":2,"name":"b","createdAt":"2021-08-04T14:56:00Z"}]
 	`))
@@ -1586,6 +1655,7 @@ func TestViewDeepUnnamed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	el, err := res.Query("#bud_target")
 	is.NoErr(err)
@@ -1599,16 +1669,16 @@ func TestViewDeepUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":3,"name":"c","createdAt":"2021-08-04T14:56:00Z"}
 	`))
 	res, err = app.Get("/teams/5/users/new")
 	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 200 OK
-		Transfer-Encoding: chunked
-		Content-Type: text/html
-	`))
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/html")
+	// Form views store a CSRF token in the session
+	is.True(strings.HasPrefix(res.Header("Set-Cookie"), "session="))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
 	html, err = el.Html()
@@ -1621,6 +1691,7 @@ func TestViewDeepUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"name":"s","createdAt":"2021-08-04T14:56:00Z"}
 	`))
@@ -1630,6 +1701,7 @@ func TestViewDeepUnnamed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
@@ -1643,16 +1715,16 @@ func TestViewDeepUnnamed(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":10,"name":"e","createdAt":"2021-08-04T14:56:00Z"}
 	`))
 	res, err = app.Get("/teams/5/users/10/edit")
 	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 200 OK
-		Transfer-Encoding: chunked
-		Content-Type: text/html
-	`))
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/html")
+	// Form views store a CSRF token in the session
+	is.True(strings.HasPrefix(res.Header("Set-Cookie"), "session="))
 	el, err = res.Query("#bud_target")
 	is.NoErr(err)
 	html, err = el.Html()
@@ -1703,6 +1775,7 @@ func TestResourceContext(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[{"id":1,"name":"a"},{"id":2,"name":"b"}]
 	`))
@@ -1711,6 +1784,7 @@ func TestResourceContext(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":1,"name":"b"}
 	`))
@@ -1719,6 +1793,7 @@ func TestResourceContext(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":2,"name":"a"}
 	`))
@@ -1727,6 +1802,7 @@ func TestResourceContext(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":2,"name":"a"}
 	`))
@@ -1735,6 +1811,7 @@ func TestResourceContext(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":2,"name":"b"}
 	`))
@@ -1743,6 +1820,7 @@ func TestResourceContext(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":2,"name":"a"}
 	`))
@@ -1773,6 +1851,7 @@ func TestEmptyActionWithView(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `<h1>hello</h1>`)
 	is.NoErr(app.Close())
@@ -1804,6 +1883,7 @@ func TestCustomActions(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `about`)
 	res, err = app.Get("/users/deactivate")
@@ -1812,6 +1892,7 @@ func TestCustomActions(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `deactivate`)
 	is.NoErr(app.Close())
@@ -1826,13 +1907,15 @@ func TestHandlerFuncs(t *testing.T) {
 		package controller
 		import "io"
 		import "net/http"
+		import "github.com/livebud/bud/package/router"
 		type Controller struct {}
 		func (c *Controller) Index() string {
 			return "hello"
 		}
 		func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusCreated)
-			w.Write([]byte(r.URL.Query().Get("foo_id")))
+			fooID, _ := router.Slots(r).Get("foo_id")
+			w.Write([]byte(fooID))
 			io.Copy(w, r.Body)
 		}
 	`
@@ -1842,11 +1925,14 @@ func TestHandlerFuncs(t *testing.T) {
 	is.NoErr(err)
 	defer app.Close()
 	// Test POST
-	res, err := app.Post("/foos/some/bars", bytes.NewBufferString("body"))
+	req, err := app.PostRequest("/foos/some/bars", bytes.NewBufferString("body"))
+	is.NoErr(err)
+	res, err := app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	diff.TestHTTP(t, res.Headers().String(), `
 		HTTP/1.1 201 Created
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 	`)
 	is.Equal(res.Body().String(), `somebody`)
 	// Test that regular actions continue to work
@@ -1855,6 +1941,7 @@ func TestHandlerFuncs(t *testing.T) {
 	diff.TestHTTP(t, res.Dump().String(), `
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"hello"
 	`)
@@ -1886,6 +1973,7 @@ func TestEnvSupport(t *testing.T) {
 	res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"buddy"
 	`)
@@ -1946,6 +2034,7 @@ func TestLoadController(t *testing.T) {
 	res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		""
 	`)
@@ -1978,6 +2067,7 @@ func TestSameNestedName(t *testing.T) {
 	res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"/users"
 	`)
@@ -1986,6 +2076,7 @@ func TestSameNestedName(t *testing.T) {
 	res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"/admins/:id/users"
 	`)
@@ -2012,6 +2103,7 @@ func TestControllerChange(t *testing.T) {
 	res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"/"
 	`)
@@ -2032,6 +2124,7 @@ func TestControllerChange(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"/"
 	`))
@@ -2068,6 +2161,7 @@ func TestRequestMap(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"post_id":10,"order":"asc","author":"Alice"}
 	`))
@@ -2076,6 +2170,7 @@ func TestRequestMap(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"post_id":10,"order":"asc","author":"Alice"}
 	`))
@@ -2085,6 +2180,7 @@ func TestRequestMap(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"post_id":10,"order":"asc","author":null}
 	`))
@@ -2135,12 +2231,13 @@ func TestComplexInput(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"ID":"123","Email":"alice@livebud.com","Op":{"name":"update","params":[{"Version":1,"update":true},{"Version":2,"update":false}]}}
 	`))
 }
 
-func TestRedirectBack(t *testing.T) {
+func TestFormError(t *testing.T) {
 	is := is.New(t)
 	ctx := context.Background()
 	td, err := testdir.Load()
@@ -2165,7 +2262,7 @@ func TestRedirectBack(t *testing.T) {
 			return errors.New("update error")
 		}
 		func (c *Controller) Delete() error {
-			return errors.New("update error")
+			return errors.New("delete error")
 		}
 	`
 	is.NoErr(td.Write(ctx))
@@ -2177,49 +2274,50 @@ func TestRedirectBack(t *testing.T) {
 	req, err := app.PostRequest("/", nil)
 	is.NoErr(err)
 	req.Header.Set("Referer", "/new")
-	res, err := app.Do(req)
-	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 303 See Other
-		Location: /new
-	`))
-	// Post request, no referer
-	req, err = app.PostRequest("/", nil)
-	is.NoErr(err)
-	res, err = app.Do(req)
+	res, err := app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 303 See Other
-		Location: /
+		HTTP/1.1 500 Internal Server Error
+		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
+		X-Content-Type-Options: nosniff
 	`))
+	is.Equal(res.Body().String(), "create error\n")
 	// Patch request
 	req, err = app.PatchRequest("/10", nil)
 	is.NoErr(err)
 	req.Header.Set("Referer", "/10/edit")
-	res, err = app.Do(req)
-	is.NoErr(err)
-	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 303 See Other
-		Location: /10/edit
-	`))
-	// Patch request, no referer
-	req, err = app.PatchRequest("/10", nil)
-	is.NoErr(err)
-	res, err = app.Do(req)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 303 See Other
-		Location: /10
+		HTTP/1.1 500 Internal Server Error
+		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
+		X-Content-Type-Options: nosniff
 	`))
+	is.Equal(res.Body().String(), "update error\n")
 	// Delete request
 	req, err = app.DeleteRequest("/10", nil)
 	is.NoErr(err)
 	req.Header.Set("Referer", "/10")
-	res, err = app.Do(req)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.DiffHeaders(`
-		HTTP/1.1 303 See Other
-		Location: /10
+		HTTP/1.1 500 Internal Server Error
+		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
+		X-Content-Type-Options: nosniff
+	`))
+	is.Equal(res.Body().String(), "delete error\n")
+	// JSON requests get a problem
+	res, err = app.PostJSON("/", nil)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 500 Internal Server Error
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
+
+		{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"create error"}
 	`))
 }
 
@@ -2297,9 +2395,10 @@ func TestInject(t *testing.T) {
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 			HTTP/1.1 500 Internal Server Error
-			Content-Type: application/json
+			Content-Type: application/problem+json
+			Vary: Accept-Encoding
 
-			{"error":"session: unable to clear"}
+			{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"session: unable to clear"}
 		`))
 	// Post request continue to work
 	res, err = app.PostJSON("/", nil)
@@ -2314,11 +2413,68 @@ func TestInject(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		1
 	`))
 }
 
+func TestSession(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		import "github.com/livebud/bud/package/session"
+		type Controller struct {
+			Session *session.Session
+		}
+		func (c *Controller) Create(name string) {
+			c.Session.Set("name", name)
+			c.Session.Flash("notice", "Welcome " + name)
+		}
+		func (c *Controller) Index() (name string, flash []*session.Flash) {
+			return c.Session.Get("name"), c.Session.Flashes()
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	req, err := app.PostRequest("/users", bytes.NewBufferString("name=Alice"))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Do(testcli.SameOrigin(req))
+	is.NoErr(err)
+	is.Equal(res.Status(), 302)
+	is.Equal(res.Header("Location"), "/users")
+	cookie := res.Header("Set-Cookie")
+	is.True(strings.HasPrefix(cookie, "session="))
+	// The value and the flash are readable on the next request
+	req, err = app.GetRequest("/users")
+	is.NoErr(err)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Cookie", strings.Split(cookie, ";")[0])
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Body().String(), `{"flash":[{"type":"notice","message":"Welcome Alice"}],"name":"Alice"}`)
+	// The flash is removed once it's been read
+	cookie = res.Header("Set-Cookie")
+	is.True(strings.HasPrefix(cookie, "session="))
+	req, err = app.GetRequest("/users")
+	is.NoErr(err)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Cookie", strings.Split(cookie, ";")[0])
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Body().String(), `{"flash":null,"name":"Alice"}`)
+	is.NoErr(app.Close())
+}
+
 func TestEscapeProps(t *testing.T) {
 	is := is.New(t)
 	ctx := context.Background()
@@ -2473,6 +2629,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"index"
 	`))
@@ -2489,6 +2646,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"index_err"
 	`))
@@ -2506,6 +2664,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"named"
 	`))
@@ -2522,6 +2681,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"named_err"
 	`))
@@ -2538,6 +2698,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":"post"}
 	`))
@@ -2554,6 +2715,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":"post_err"}
 	`))
@@ -2570,6 +2732,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":"named_post"}
 	`))
@@ -2586,6 +2749,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"id":"named_post_err"}
 	`))
@@ -2602,6 +2766,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"SlugID":"article"}
 	`))
@@ -2618,6 +2783,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"SlugID":"article_err"}
 	`))
@@ -2634,6 +2800,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"SlugID":"named_article"}
 	`))
@@ -2650,6 +2817,7 @@ func TestProtocol(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		{"SlugID":"named_article_err"}
 	`))
@@ -2714,6 +2882,7 @@ func TestIndexControllerWithRootIndexAction(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[]
 	`))
@@ -2722,6 +2891,7 @@ func TestIndexControllerWithRootIndexAction(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[]
 	`))
@@ -2730,6 +2900,7 @@ func TestIndexControllerWithRootIndexAction(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[]
 	`))
@@ -2738,6 +2909,7 @@ func TestIndexControllerWithRootIndexAction(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[]
 	`))
@@ -2769,6 +2941,7 @@ func TestCreateRouteAndControllerAndView(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"/"
 	`))
@@ -2793,6 +2966,7 @@ func TestCreateRouteAndControllerAndView(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"/10"
 	`))
@@ -2814,6 +2988,7 @@ func TestCreateRouteAndControllerAndView(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		"/posts"
 	`))
@@ -2832,6 +3007,7 @@ func TestCreateRouteAndControllerAndView(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	sel, err := res.Query("#bud_target")
 	is.NoErr(err)
@@ -2852,6 +3028,7 @@ func TestCreateRouteAndControllerAndView(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 404 Not Found
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 		X-Content-Type-Options: nosniff
 	`))
 	// Create the accompanying route
@@ -2875,6 +3052,7 @@ func TestCreateRouteAndControllerAndView(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	sel, err = res.Query("#bud_target")
 	is.NoErr(err)
@@ -2918,6 +3096,7 @@ func TestDeleteRouteAndControllerAndView(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	sel, err := res.Query("#bud_target")
 	is.NoErr(err)
@@ -2931,6 +3110,7 @@ func TestDeleteRouteAndControllerAndView(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	sel, err = res.Query("#bud_target")
 	is.NoErr(err)
@@ -2949,6 +3129,7 @@ func TestDeleteRouteAndControllerAndView(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.NotIn(res.Body().String(), "<h1>Show Posts</h1>")
 	// Delete the controller
@@ -2963,6 +3144,7 @@ func TestDeleteRouteAndControllerAndView(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 404 Not Found
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 		X-Content-Type-Options: nosniff
 	`))
 	// Re-test /posts/10
@@ -2971,6 +3153,7 @@ func TestDeleteRouteAndControllerAndView(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 404 Not Found
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 		X-Content-Type-Options: nosniff
 	`))
 }
@@ -2999,6 +3182,7 @@ func TestUpdateBodyAndSignatureAndRoute(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `Hello Users!`)
 	// Update controller body
@@ -3020,6 +3204,7 @@ func TestUpdateBodyAndSignatureAndRoute(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `Hello Humans!`)
 	// Update controller signature
@@ -3041,6 +3226,7 @@ func TestUpdateBodyAndSignatureAndRoute(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `Hello Mark!`)
 	// Update route
@@ -3062,6 +3248,7 @@ func TestUpdateBodyAndSignatureAndRoute(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 404 Not Found
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 		X-Content-Type-Options: nosniff
 	`))
 	// Try new route
@@ -3070,6 +3257,777 @@ func TestUpdateBodyAndSignatureAndRoute(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), `/10`)
 }
+
+func TestRouteDirective(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/webhooks/controller.go"] = `
+		package webhooks
+		type Controller struct {}
+		// Stripe receives webhooks from stripe
+		//bud:route POST stripe/:event
+		func (c *Controller) Stripe(event string) string { return "stripe " + event }
+		//bud:route PUT /rpc/ping
+		func (c *Controller) Ping() string { return "pong" }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.PostJSON("/webhooks/stripe/charge", nil)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+		Vary: Accept-Encoding
+
+		"stripe charge"
+	`))
+	res, err = app.PutJSON("/rpc/ping", nil)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+		Vary: Accept-Encoding
+
+		"pong"
+	`))
+	// The default RESTful route is no longer registered
+	res, err = app.Get("/webhooks/stripe")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.NoErr(app.Close())
+}
+
+func TestRouteDirectiveConflict(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		func (c *Controller) Index() string { return "users" }
+		//bud:route GET /users
+		func (c *Controller) List() string { return "list" }
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `controller: route "GET /users" conflicts between /users/index and /users/list`)
+}
+
+func TestValidate422(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		type User struct {
+			ID int ` + "`" + `json:"id"` + "`" + `
+			Email string ` + "`" + `json:"email"` + "`" + `
+		}
+		//bud:validate email required,email
+		func (c *Controller) Create(email string) *User {
+			return &User{1, email}
+		}
+		type Input struct {
+			Name string ` + "`" + `json:"name" validate:"required,max=3"` + "`" + `
+		}
+		func (c *Controller) Update(in *Input) {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.PostJSON("/users", bytes.NewBufferString(`{"email":"nope"}`))
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 422 Unprocessable Entity
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
+
+		{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"request: invalid input. email must be a valid email address","fields":{"email":["must be a valid email address"]}}
+	`))
+	res, err = app.PostJSON("/users", bytes.NewBufferString(`{"email":"a@b.co"}`))
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+		Vary: Accept-Encoding
+
+		{"id":1,"email":"a@b.co"}
+	`))
+	res, err = app.PatchJSON("/users/1", bytes.NewBufferString(`{}`))
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 422 Unprocessable Entity
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
+
+		{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"request: invalid input. name is required","fields":{"name":["is required"]}}
+	`))
+	is.NoErr(app.Close())
+}
+
+func TestValidateDirectiveQuotes(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		//bud:validate role oneof="admin" member
+		func (c *Controller) Create(role string) string {
+			return role
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.PostJSON("/users", bytes.NewBufferString(`{"role":"\"admin\""}`))
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+		Vary: Accept-Encoding
+
+		"\"admin\""
+	`))
+	is.NoErr(app.Close())
+	// Backticks can't be written into the generated struct tag
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		//bud:validate role oneof=` + "`admin`" + `
+		func (c *Controller) Create(role string) string {
+			return role
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "rules can't contain backticks")
+}
+
+func TestValidateInvalidRules(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		//bud:validate age min=abc
+		func (c *Controller) Create(age int) int {
+			return age
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `invalid rules "min=abc" in "bud:validate" directive on /users/create. "min" expects a number, got "abc"`)
+	// Tags on struct inputs and the structs within them are checked too
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		type Address struct {
+			Country string ` + "`" + `json:"country" validate:"oneof="` + "`" + `
+		}
+		type User struct {
+			Name string ` + "`" + `json:"name" validate:"required"` + "`" + `
+			Address *Address ` + "`" + `json:"address"` + "`" + `
+		}
+		func (c *Controller) Create(in *User) *User {
+			return in
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `invalid validate tag on Address.Country. "oneof" expects at least one option`)
+}
+
+func TestValidateRerenderForm(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.NodeModules["svelte"] = versions.Svelte
+	td.Files["view/users/new.svelte"] = `
+		<script>
+			export let input = {}
+			export let errors = {}
+			export let csrf = ""
+		</script>
+		<input type="hidden" name="_csrf" value={csrf} />
+		<input name="email" value={input.email || ""} />
+		{#each errors.email || [] as error}
+		<p class="error">{error}</p>
+		{/each}
+	`
+	td.Files["controller/users/controller.go"] = `
+		package users
+		type Controller struct {}
+		func (c *Controller) New() {}
+		//bud:validate email required,email
+		func (c *Controller) Create(email string) {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	req, err := app.PostRequest("/users", bytes.NewBufferString("email=nope"))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Do(testcli.SameOrigin(req))
+	is.NoErr(err)
+	is.Equal(res.Status(), 422)
+	el, err := res.Query(".error")
+	is.NoErr(err)
+	is.Equal(el.Text(), "must be a valid email address")
+	el, err = res.Query(`input[name="email"]`)
+	is.NoErr(err)
+	is.Equal(el.AttrOr("value", ""), "nope")
+	// Re-rendered forms receive a CSRF token
+	el, err = res.Query(`input[name="_csrf"]`)
+	is.NoErr(err)
+	is.True(el.AttrOr("value", "") != "")
+	// Forms from other sites without the token are forbidden
+	req, err = app.PostRequest("/users", bytes.NewBufferString("email=nope"))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 403)
+	is.NoErr(app.Close())
+}
+
+func TestUpload(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = `
+		package users
+		import (
+			"io"
+			"github.com/livebud/bud/package/upload"
+		)
+		type Controller struct {}
+		type User struct {
+			Name string ` + "`" + `json:"name"` + "`" + `
+			Avatar string ` + "`" + `json:"avatar"` + "`" + `
+		}
+		//bud:upload max=1KB
+		func (c *Controller) Create(name string, avatar *upload.File) (*User, error) {
+			file, err := avatar.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return nil, err
+			}
+			return &User{name, avatar.Filename + ":" + string(data)}, nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	is.NoErr(writer.WriteField("name", "bud"))
+	part, err := writer.CreateFormFile("avatar", "avatar.png")
+	is.NoErr(err)
+	part.Write([]byte("png"))
+	is.NoErr(writer.Close())
+	req, err := app.PostRequest("/users", body)
+	is.NoErr(err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	res, err := app.Do(testcli.SameOrigin(req))
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+		Vary: Accept-Encoding
+
+		{"name":"bud","avatar":"avatar.png:png"}
+	`))
+	// Too large
+	body = new(bytes.Buffer)
+	writer = multipart.NewWriter(body)
+	part, err = writer.CreateFormFile("avatar", "avatar.png")
+	is.NoErr(err)
+	part.Write(bytes.Repeat([]byte("a"), 2048))
+	is.NoErr(writer.Close())
+	req, err = app.PostRequest("/users", body)
+	is.NoErr(err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	res, err = app.Do(testcli.SameOrigin(req))
+	is.NoErr(err)
+	is.Equal(res.Status(), 400)
+	is.In(res.Body().String(), "request: multipart body is larger than 1024 bytes")
+	is.NoErr(app.Close())
+}
+
+func TestErrorStatus(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.NodeModules["svelte"] = versions.Svelte
+	td.Files["view/users/index.svelte"] = `<h1>users</h1>`
+	td.Files["view/users/Error.svelte"] = `
+		<script>
+			export let status = 500
+			export let message = ""
+		</script>
+		<h1>{status}: {message}</h1>
+	`
+	td.Files["controller/users/controller.go"] = `
+		package users
+		import (
+			"fmt"
+			"github.com/livebud/bud/framework/controller/controllerrt/response"
+		)
+		type Controller struct {}
+		type User struct {
+			ID int ` + "`" + `json:"id"` + "`" + `
+		}
+		func (c *Controller) Index() []*User {
+			return []*User{}
+		}
+		func (c *Controller) Show(id int) (*User, error) {
+			return nil, fmt.Errorf("user %d: %w", id, response.ErrNotFound)
+		}
+		func (c *Controller) Delete(id int) error {
+			return response.ErrForbidden
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.GetJSON("/users/10")
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 404 Not Found
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
+
+		{"type":"about:blank","title":"Not Found","status":404,"detail":"user 10: not found"}
+	`))
+	res, err = app.Get("/users/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 404)
+	is.In(res.Body().String(), "<h1>404: user 10: not found</h1>")
+	res, err = app.DeleteJSON("/users/10", nil)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 403 Forbidden
+		Content-Type: application/problem+json
+		Vary: Accept-Encoding
+
+		{"type":"about:blank","title":"Forbidden","status":403,"detail":"forbidden"}
+	`))
+	is.NoErr(app.Close())
+}
+
+func TestStreamResults(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/feeds/controller.go"] = `
+		package feeds
+		import (
+			"context"
+			"io"
+			"strings"
+		)
+		type Controller struct {}
+		type Progress struct {
+			Percent int ` + "`" + `json:"percent"` + "`" + `
+		}
+		func (c *Controller) Index(ctx context.Context) (<-chan *Progress, error) {
+			ch := make(chan *Progress)
+			go func() {
+				defer close(ch)
+				for i := 0; i <= 100; i += 50 {
+					select {
+					case ch <- &Progress{i}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return ch, nil
+		}
+		func (c *Controller) Show(id int) func(yield func(int) bool) {
+			return func(yield func(int) bool) {
+				for i := 0; i < id; i++ {
+					if !yield(i) {
+						return
+					}
+				}
+			}
+		}
+		func (c *Controller) Export() (io.Reader, error) {
+			return strings.NewReader("id,name\n1,alice\n"), nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/feeds")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/event-stream")
+	is.Equal(res.Body().String(), "data: {\"percent\":0}\n\ndata: {\"percent\":50}\n\ndata: {\"percent\":100}\n\n")
+	res, err = app.Get("/feeds/3")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/event-stream")
+	is.Equal(res.Body().String(), "data: 0\n\ndata: 1\n\ndata: 2\n\n")
+	res, err = app.Get("/feeds/export")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("Content-Type"), "text/plain; charset=utf-8")
+	is.Equal(res.Body().String(), "id,name\n1,alice\n")
+	is.NoErr(app.Close())
+}
+
+func TestStreamWithOtherResults(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() (<-chan int, int) {
+			return nil, 0
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "streamed results can only be returned with an error")
+}
+
+func TestWebSocket(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["hub/hub.go"] = `
+		package hub
+		type Hub struct {
+			Prefix string
+		}
+		func New() *Hub {
+			return &Hub{"room"}
+		}
+	`
+	td.Files["controller/rooms/controller.go"] = `
+		package rooms
+		import (
+			"context"
+			"fmt"
+			"app.com/hub"
+			"github.com/livebud/bud/package/websocket"
+		)
+		type Controller struct {
+			Hub *hub.Hub
+		}
+		type Message struct {
+			Text string ` + "`" + `json:"text"` + "`" + `
+		}
+		func (c *Controller) Show(ctx context.Context, conn *websocket.Conn, id int, name string) error {
+			for {
+				var msg Message
+				if err := conn.Receive(&msg); err != nil {
+					return err
+				}
+				msg.Text = fmt.Sprintf("%s %d %s: %s", c.Hub.Prefix, id, name, msg.Text)
+				if err := conn.Send(&msg); err != nil {
+					return err
+				}
+			}
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	ws, err := app.Dial(ctx, "/rooms/10?name=alice")
+	is.NoErr(err)
+	defer ws.Close()
+	is.NoErr(websocket.JSON.Send(ws, map[string]string{"text": "hi"}))
+	var msg map[string]string
+	is.NoErr(websocket.JSON.Receive(ws, &msg))
+	is.Equal(msg["text"], "room 10 alice: hi")
+	is.NoErr(websocket.JSON.Send(ws, map[string]string{"text": "bye"}))
+	is.NoErr(websocket.JSON.Receive(ws, &msg))
+	is.Equal(msg["text"], "room 10 alice: bye")
+	// Regular requests aren't upgraded
+	res, err := app.Get("/rooms/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 400)
+	// Inputs are still validated before upgrading
+	res, err = app.Get("/rooms/abc")
+	is.NoErr(err)
+	is.Equal(res.Status(), 400)
+	is.NoErr(app.Close())
+}
+
+func TestWebSocketResults(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		import "github.com/livebud/bud/package/websocket"
+		type Controller struct {}
+		func (c *Controller) Chat(conn *websocket.Conn) (string, error) {
+			return "", nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "websocket action /chat can only return an error")
+}
+
+func TestWebSocketMethod(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		import "github.com/livebud/bud/package/websocket"
+		type Controller struct {}
+		func (c *Controller) Create(conn *websocket.Conn) error {
+			return nil
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "websocket action /create must be a GET request, not POST")
+}
+
+func TestMiddleware(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/admin/controller.go"] = `
+		package admin
+		import "net/http"
+		type Controller struct {}
+		func (c *Controller) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "secret" {
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("X-Admin", "true")
+				next.ServeHTTP(w, r)
+			})
+		}
+		func (c *Controller) Audit(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Audit", "true")
+				next.ServeHTTP(w, r)
+			})
+		}
+		func (c *Controller) Index() string {
+			return "admin"
+		}
+		//bud:middleware Audit
+		func (c *Controller) Show(id int) int {
+			return id
+		}
+	`
+	td.Files["controller/admin/users/controller.go"] = `
+		package users
+		import "net/http"
+		type Controller struct {}
+		func (c *Controller) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Users", "true")
+				next.ServeHTTP(w, r)
+			})
+		}
+		func (c *Controller) Index() []string {
+			return []string{"alice"}
+		}
+	`
+	td.Files["controller/posts/controller.go"] = `
+		package posts
+		type Controller struct {}
+		func (c *Controller) Index() string {
+			return "posts"
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	get := func(path, auth string) *testcli.Response {
+		req, err := http.NewRequest(http.MethodGet, "http://host"+path, nil)
+		is.NoErr(err)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", auth)
+		res, err := app.Do(req)
+		is.NoErr(err)
+		return res
+	}
+	// Controller middleware wraps every action
+	res := get("/admin", "")
+	is.Equal(res.Status(), 401)
+	res = get("/admin", "secret")
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Admin"), "true")
+	is.Equal(res.Header("X-Audit"), "")
+	is.Equal(res.Body().String(), `"admin"`)
+	// Action middleware only wraps that action
+	res = get("/admin/10", "secret")
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Admin"), "true")
+	is.Equal(res.Header("X-Audit"), "true")
+	is.Equal(res.Body().String(), `10`)
+	// Nested controllers inherit their parent's middleware
+	res = get("/admin/10/users", "")
+	is.Equal(res.Status(), 401)
+	res = get("/admin/10/users", "secret")
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Admin"), "true")
+	is.Equal(res.Header("X-Users"), "true")
+	is.Equal(res.Body().String(), `["alice"]`)
+	// Other controllers aren't wrapped
+	res = get("/posts", "")
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Admin"), "")
+	is.NoErr(app.Close())
+}
+
+func TestMiddlewareUnknown(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		//bud:middleware Auth
+		func (c *Controller) Index() string {
+			return ""
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `unknown middleware "Auth" in "bud:middleware" directive on /index`)
+}
+
+func TestMiddlewareSignature(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Middleware() string {
+			return ""
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), "expected Middleware to have the signature func(next http.Handler) http.Handler")
+}
+
+func TestBindTags(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/tokens/controller.go"] = `
+		package tokens
+		import "fmt"
+		type Controller struct {}
+		type ShowInput struct {
+			ID      int    ` + "`" + `json:"id" path:"id"` + "`" + `
+			Key     string ` + "`" + `json:"key" header:"X-Api-Key"` + "`" + `
+			Session string ` + "`" + `json:"session" cookie:"session"` + "`" + `
+			Format  string ` + "`" + `json:"format"` + "`" + `
+		}
+		func (c *Controller) Show(in *ShowInput) string {
+			return fmt.Sprintf("%d %s %s %s", in.ID, in.Key, in.Session, in.Format)
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	// Tagged fields can't be set from the query string
+	req, err := app.GetRequest("/tokens/7?id=9&key=spoofed&session=spoofed&format=txt")
+	is.NoErr(err)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Api-Key", "secret")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	res, err := app.Do(req)
+	is.NoErr(err)
+	is.NoErr(res.Diff(`
+		HTTP/1.1 200 OK
+		Content-Type: application/json
+		Vary: Accept-Encoding
+
+		"7 secret abc txt"
+	`))
+	// Slots that fail to parse are bad requests
+	req, err = app.GetRequest("/tokens/abc")
+	is.NoErr(err)
+	req.Header.Set("Accept", "application/json")
+	res, err = app.Do(req)
+	is.NoErr(err)
+	is.Equal(res.Status(), 400)
+}
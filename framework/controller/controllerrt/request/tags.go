@@ -0,0 +1,147 @@
+package request
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/ajg/form"
+	"github.com/livebud/bud/package/router"
+)
+
+// unmarshalSlots decodes the router's path slots into the matching fields.
+// Slots are decoded after the body and query string so they take precedence.
+func unmarshalSlots(r *http.Request, v interface{}) error {
+	slots := router.Slots(r)
+	if len(slots) == 0 {
+		return nil
+	}
+	dec := form.NewDecoder(nil)
+	dec.IgnoreCase(true)
+	dec.IgnoreUnknownKeys(true)
+	return dec.DecodeValues(v, slots.Values())
+}
+
+// Tag sources that fields can be explicitly bound to
+var sources = []string{"path", "header", "cookie"}
+
+// unmarshalTags binds the fields tagged with `path:"id"`, `header:"X-Api-Key"`
+// or `cookie:"session"` to their source. Tagged fields are reset first, so
+// they can't be filled from the body or the query string instead.
+func unmarshalTags(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		for _, source := range sources {
+			name, ok := field.Tag.Lookup(source)
+			if !ok || name == "" {
+				continue
+			}
+			value := rv.Field(i)
+			value.Set(reflect.Zero(field.Type))
+			values := lookup(r, source, name)
+			if len(values) == 0 {
+				continue
+			}
+			if err := setValues(value, values); err != nil {
+				return fmt.Errorf("request: unable to unmarshal %s %q into %s. %w", source, name, field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// lookup the values of name from the source
+func lookup(r *http.Request, source, name string) []string {
+	switch source {
+	case "path":
+		if value, ok := router.Slots(r).Get(name); ok {
+			return []string{value}
+		}
+	case "header":
+		return r.Header.Values(name)
+	case "cookie":
+		if cookie, err := r.Cookie(name); err == nil {
+			return []string{cookie.Value}
+		}
+	}
+	return nil
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setValues converts the string values into the field's type. Slices are
+// filled with every value, otherwise the first value is used.
+func setValues(value reflect.Value, values []string) error {
+	if value.Kind() == reflect.Slice && !reflect.PtrTo(value.Type()).Implements(textUnmarshaler) {
+		slice := reflect.MakeSlice(value.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setValue(slice.Index(i), v); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+	return setValue(value, values[0])
+}
+
+func setValue(value reflect.Value, s string) error {
+	if value.Kind() == reflect.Ptr {
+		ptr := reflect.New(value.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+		value.Set(ptr)
+		return nil
+	}
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshaler) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
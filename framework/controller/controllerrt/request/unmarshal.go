@@ -2,17 +2,68 @@ package request
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strings"
 
 	"github.com/ajg/form"
+	"github.com/livebud/bud/package/upload"
 )
 
+// Default multipart limits
+const (
+	DefaultMaxMemory int64 = 32 << 20  // 32 MB
+	DefaultMaxSize   int64 = 100 << 20 // 100 MB
+)
+
+type Option = func(o *option)
+
+type option struct {
+	maxMemory int64
+	maxSize   int64
+	writer    http.ResponseWriter
+}
+
+// WithMaxMemory sets the number of bytes of a multipart body that are stored
+// in memory. The rest of the files are stored in temporary files on disk.
+func WithMaxMemory(n int64) Option {
+	return func(o *option) {
+		o.maxMemory = n
+	}
+}
+
+// WithMaxSize sets the maximum number of bytes of a multipart body, including
+// the files stored on disk.
+func WithMaxSize(n int64) Option {
+	return func(o *option) {
+		o.maxSize = n
+	}
+}
+
+// WithResponseWriter sets the response writer of the request, so the server
+// can close the connection when a multipart body is larger than the max size.
+func WithResponseWriter(w http.ResponseWriter) Option {
+	return func(o *option) {
+		o.writer = w
+	}
+}
+
 // Unmarshal the request data into v
-func Unmarshal(r *http.Request, v interface{}) error {
-	err := unmarshalBody(r, v)
+func Unmarshal(r *http.Request, v interface{}, options ...Option) error {
+	opt := &option{
+		maxMemory: DefaultMaxMemory,
+		maxSize:   DefaultMaxSize,
+	}
+	for _, option := range options {
+		option(opt)
+	}
+	err := unmarshalBody(r, v, opt)
 	if err != nil {
 		return err
 	}
@@ -20,10 +71,18 @@ func Unmarshal(r *http.Request, v interface{}) error {
 	if err != nil {
 		return err
 	}
+	err = unmarshalSlots(r, v)
+	if err != nil {
+		return err
+	}
+	err = unmarshalTags(r, v)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
-func unmarshalBody(r *http.Request, v interface{}) error {
+func unmarshalBody(r *http.Request, v interface{}, opt *option) error {
 	contentType := r.Header.Get("Content-Type")
 	if contentType == "" {
 		return nil
@@ -37,6 +96,8 @@ func unmarshalBody(r *http.Request, v interface{}) error {
 		return unmarshalJSON(r.Body, v)
 	case "application/x-www-form-urlencoded":
 		return unmarshalForm(r, v)
+	case "multipart/form-data":
+		return unmarshalMultipart(r, v, opt)
 	}
 	return nil
 }
@@ -68,3 +129,95 @@ func unmarshalJSON(r io.Reader, v interface{}) error {
 	}
 	return json.Unmarshal(data, v)
 }
+
+func unmarshalMultipart(r *http.Request, v interface{}, opt *option) error {
+	if r.MultipartForm == nil {
+		if r.Body != nil && opt.maxSize > 0 {
+			r.Body = http.MaxBytesReader(opt.writer, r.Body, opt.maxSize)
+		}
+		if err := r.ParseMultipartForm(opt.maxMemory); err != nil {
+			if errors.Is(err, multipart.ErrMessageTooLarge) || errors.As(err, new(*http.MaxBytesError)) {
+				return fmt.Errorf("request: multipart body is larger than %d bytes. %w", opt.maxSize, err)
+			}
+			return err
+		}
+	}
+	dec := form.NewDecoder(nil)
+	dec.IgnoreCase(true)
+	dec.IgnoreUnknownKeys(true)
+	if err := dec.DecodeValues(v, url.Values(r.MultipartForm.Value)); err != nil {
+		return err
+	}
+	return unmarshalFiles(r.MultipartForm.File, v)
+}
+
+var fileType = reflect.TypeOf(upload.File{})
+
+// unmarshalFiles sets the *upload.File, upload.File and []*upload.File fields
+// whose names match the uploaded files.
+func unmarshalFiles(files map[string][]*multipart.FileHeader, v interface{}) error {
+	if len(files) == 0 {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		headers := findFiles(files, field)
+		if len(headers) == 0 {
+			continue
+		}
+		value := rv.Field(i)
+		switch {
+		case field.Type == fileType:
+			value.Set(reflect.ValueOf(upload.New(headers[0])).Elem())
+		case field.Type == reflect.PtrTo(fileType):
+			value.Set(reflect.ValueOf(upload.New(headers[0])))
+		case field.Type == reflect.SliceOf(reflect.PtrTo(fileType)):
+			uploads := make([]*upload.File, len(headers))
+			for i, header := range headers {
+				uploads[i] = upload.New(header)
+			}
+			value.Set(reflect.ValueOf(uploads))
+		default:
+			return fmt.Errorf("request: unable to unmarshal uploaded file into %s of type %s", field.Name, field.Type)
+		}
+	}
+	return nil
+}
+
+// Find the files by the field's JSON name or the field name
+func findFiles(files map[string][]*multipart.FileHeader, field reflect.StructField) []*multipart.FileHeader {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		name = field.Name
+	} else if name == "-" {
+		return nil
+	}
+	for key, headers := range files {
+		if strings.EqualFold(key, name) {
+			return headers
+		}
+	}
+	return nil
+}
+
+// Cleanup removes any temporary files created while parsing a multipart body
+func Cleanup(r *http.Request) error {
+	if r.MultipartForm == nil {
+		return nil
+	}
+	return r.MultipartForm.RemoveAll()
+}
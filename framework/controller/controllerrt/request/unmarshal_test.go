@@ -2,11 +2,21 @@ package request_test
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/livebud/bud/framework/controller/controllerrt/request"
 	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/router"
+	"github.com/livebud/bud/package/upload"
 )
 
 func TestJSONEmpty(t *testing.T) {
@@ -396,3 +406,203 @@ func TestJSONGet(t *testing.T) {
 	is.Equal("asc", s.Order)
 	is.Equal("Alice", s.Author)
 }
+
+func multipartRequest(t testing.TB, fields map[string]string, files map[string]string) *http.Request {
+	t.Helper()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for key, data := range files {
+		part, err := writer.CreateFormFile(key, key+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(data))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func TestMultipart(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Name   string         `json:"name"`
+		Avatar *upload.File   `json:"avatar"`
+		Docs   []*upload.File `json:"docs"`
+		Other  upload.File
+	}
+	s := S{}
+	r := multipartRequest(t, map[string]string{"name": "bud"}, map[string]string{"avatar": "hello", "other": "world"})
+	defer Cleanup(r)
+	err := Unmarshal(r, &s)
+	is.NoErr(err)
+	is.Equal(s.Name, "bud")
+	is.True(s.Avatar != nil)
+	is.Equal(s.Avatar.Filename, "avatar.txt")
+	is.Equal(s.Avatar.ContentType, "application/octet-stream")
+	is.Equal(s.Avatar.Size, int64(5))
+	file, err := s.Avatar.Open()
+	is.NoErr(err)
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	is.NoErr(err)
+	is.Equal(string(data), "hello")
+	is.Equal(s.Other.Filename, "other.txt")
+	is.Equal(len(s.Docs), 0)
+}
+
+func TestMultipartTooLarge(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Avatar *upload.File `json:"avatar"`
+	}
+	s := S{}
+	r := multipartRequest(t, nil, map[string]string{"avatar": strings.Repeat("a", 1024)})
+	defer Cleanup(r)
+	err := Unmarshal(r, &s, WithMaxSize(512), WithResponseWriter(httptest.NewRecorder()))
+	is.True(err != nil)
+	is.In(err.Error(), "request: multipart body is larger than 512 bytes")
+	is.True(errors.As(err, new(*http.MaxBytesError)))
+}
+
+func TestJSONUpload(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Avatar *upload.File `json:"avatar"`
+	}
+	s := S{}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"avatar":{"filename":"a.png"}}`))
+	r.Header.Set("Content-Type", "application/json")
+	err := Unmarshal(r, &s)
+	is.NoErr(err)
+	is.Equal(s.Avatar.Filename, "a.png")
+	// There's no uploaded file behind a file decoded from JSON
+	file, err := s.Avatar.Open()
+	is.Equal(file, nil)
+	is.True(errors.Is(err, upload.ErrNoFile))
+}
+
+func TestMultipartDisk(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Avatar *upload.File `json:"avatar"`
+	}
+	s := S{}
+	r := multipartRequest(t, nil, map[string]string{"avatar": strings.Repeat("a", 1024)})
+	err := Unmarshal(r, &s, WithMaxMemory(1))
+	is.NoErr(err)
+	file, err := s.Avatar.Open()
+	is.NoErr(err)
+	osFile, ok := file.(*os.File)
+	is.True(ok)
+	name := osFile.Name()
+	is.NoErr(file.Close())
+	_, err = os.Stat(name)
+	is.NoErr(err)
+	is.NoErr(Cleanup(r))
+	_, err = os.Stat(name)
+	is.True(errors.Is(err, fs.ErrNotExist))
+}
+
+// route the request through a router so the path slots are set
+func route(t testing.TB, route string, r *http.Request, v interface{}) error {
+	t.Helper()
+	var err error
+	rt := router.New()
+	if err := rt.Add(r.Method, route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = Unmarshal(r, v)
+	})); err != nil {
+		t.Fatal(err)
+	}
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+	return err
+}
+
+func TestSlots(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		PostID int    `json:"post_id"`
+		Order  string `json:"order"`
+	}
+	s := S{}
+	r := httptest.NewRequest("GET", "/posts/10?post_id=20&order=asc", nil)
+	err := route(t, "/posts/:post_id", r, &s)
+	is.NoErr(err)
+	is.Equal(10, s.PostID)
+	is.Equal("asc", s.Order)
+}
+
+func TestPathTag(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		ID   int    `json:"id" path:"user_id"`
+		Name string `json:"name"`
+	}
+	s := S{}
+	r := httptest.NewRequest("POST", "/users/10?id=20", strings.NewReader(`{"id":30,"name":"Alice"}`))
+	r.Header.Set("Content-Type", "application/json")
+	err := route(t, "/users/:user_id", r, &s)
+	is.NoErr(err)
+	is.Equal(10, s.ID)
+	is.Equal("Alice", s.Name)
+}
+
+func TestHeaderTag(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Key     string   `json:"key" header:"X-Api-Key"`
+		Version *int     `header:"X-Version"`
+		Tags    []string `header:"X-Tag"`
+		Missing string   `header:"X-Missing"`
+	}
+	s := S{}
+	r := httptest.NewRequest("GET", "/?key=spoofed&missing=spoofed", nil)
+	r.Header.Set("X-Api-Key", "secret")
+	r.Header.Set("X-Version", "2")
+	r.Header.Add("X-Tag", "a")
+	r.Header.Add("X-Tag", "b")
+	err := Unmarshal(r, &s)
+	is.NoErr(err)
+	is.Equal("secret", s.Key)
+	is.True(s.Version != nil)
+	is.Equal(2, *s.Version)
+	is.Equal([]string{"a", "b"}, s.Tags)
+	is.Equal("", s.Missing)
+}
+
+func TestCookieTag(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Session string    `cookie:"session"`
+		Visited time.Time `cookie:"visited"`
+	}
+	s := S{}
+	r := httptest.NewRequest("GET", "/?session=spoofed", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	r.AddCookie(&http.Cookie{Name: "visited", Value: "2022-01-02T03:04:05Z"})
+	err := Unmarshal(r, &s)
+	is.NoErr(err)
+	is.Equal("abc", s.Session)
+	is.Equal(2022, s.Visited.Year())
+}
+
+func TestTagInvalid(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Version int `header:"X-Version"`
+	}
+	s := S{}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Version", "two")
+	err := Unmarshal(r, &s)
+	is.True(err != nil)
+	is.In(err.Error(), `request: unable to unmarshal header "X-Version" into Version`)
+}
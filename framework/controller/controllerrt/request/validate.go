@@ -0,0 +1,271 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is returned when the input doesn't pass validation. Fields
+// maps each invalid field to its error messages.
+type ValidationError struct {
+	Fields map[string][]string `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, key+" "+strings.Join(e.Fields[key], ", "))
+	}
+	return "request: invalid input. " + strings.Join(messages, ". ")
+}
+
+// StatusCode of validation errors is 422 Unprocessable Entity
+func (e *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e *ValidationError) add(field, message string) {
+	e.Fields[field] = append(e.Fields[field], message)
+}
+
+// Validate the input using `validate:"..."` struct tags. Rules are separated
+// by commas, e.g. `validate:"required,email,max=80"`.
+//
+// Supported rules: required, email, url, min=n, max=n, len=n, oneof=a b c.
+// For strings, slices and maps min, max and len check the length. For numbers
+// they check the value. Empty strings, slices and maps are only checked by
+// required.
+func Validate(in interface{}) error {
+	v := reflect.ValueOf(in)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	verr := &ValidationError{map[string][]string{}}
+	if err := validateStruct(verr, "", v); err != nil {
+		return err
+	}
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func validateStruct(verr *ValidationError, prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := prefix + fieldName(field)
+		value := v.Field(i)
+		if tag, ok := field.Tag.Lookup("validate"); ok && tag != "-" {
+			if err := validateField(verr, name, value, tag); err != nil {
+				return fmt.Errorf("request: invalid validate tag on %s.%s. %w", t.Name(), field.Name, err)
+			}
+		}
+		// Recurse into nested structs
+		for value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct {
+			nestedPrefix := name + "."
+			if field.Anonymous {
+				nestedPrefix = prefix
+			}
+			if err := validateStruct(verr, nestedPrefix, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldName uses the JSON name if there is one
+func fieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+func validateField(verr *ValidationError, name string, value reflect.Value, tag string) error {
+	rules := strings.Split(tag, ",")
+	// Nil pointers are only invalid if they're required
+	if value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			for _, rule := range rules {
+				if strings.TrimSpace(rule) == "required" {
+					verr.add(name, "is required")
+				}
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		key, arg, _ := strings.Cut(rule, "=")
+		if err := checkRule(key, arg); err != nil {
+			return err
+		}
+		message, err := check(key, arg, value)
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			verr.add(name, message)
+		}
+	}
+	return nil
+}
+
+// CheckTag checks that each rule in a validate tag is supported and has a
+// valid argument. This doesn't depend on the value, so it can be checked when
+// the app is generated.
+func CheckTag(tag string) error {
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		key, arg, _ := strings.Cut(rule, "=")
+		if err := checkRule(key, arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRule checks that the rule is supported and has a valid argument
+func checkRule(rule, arg string) error {
+	switch rule {
+	case "required", "email", "url":
+		return nil
+	case "min", "max", "len":
+		if _, err := strconv.ParseFloat(arg, 64); err != nil {
+			return fmt.Errorf("%q expects a number, got %q", rule, arg)
+		}
+		return nil
+	case "oneof":
+		if len(strings.Fields(arg)) == 0 {
+			return fmt.Errorf("%q expects at least one option", rule)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+}
+
+// check a single rule, returning a message if the value is invalid. The rule
+// has already been checked by checkRule.
+func check(rule, arg string, v reflect.Value) (message string, err error) {
+	switch rule {
+	case "required":
+		if v.IsZero() {
+			return "is required", nil
+		}
+		return "", nil
+	case "email":
+		s, err := stringOf(rule, v)
+		if err != nil {
+			return "", err
+		}
+		if s == "" {
+			return "", nil
+		}
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be a valid email address", nil
+		}
+		return "", nil
+	case "url":
+		s, err := stringOf(rule, v)
+		if err != nil {
+			return "", err
+		}
+		if s == "" {
+			return "", nil
+		}
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid URL", nil
+		}
+		return "", nil
+	case "min", "max", "len":
+		n, _ := strconv.ParseFloat(arg, 64)
+		return checkSize(rule, arg, n, v)
+	case "oneof":
+		options := strings.Fields(arg)
+		s := fmt.Sprint(v.Interface())
+		if v.IsZero() {
+			return "", nil
+		}
+		for _, option := range options {
+			if s == option {
+				return "", nil
+			}
+		}
+		return "must be one of " + strings.Join(options, ", "), nil
+	default:
+		return "", fmt.Errorf("unknown rule %q", rule)
+	}
+}
+
+func stringOf(rule string, v reflect.Value) (string, error) {
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("%q only applies to strings, not %s", rule, v.Type())
+	}
+	return v.String(), nil
+}
+
+func checkSize(rule, arg string, n float64, v reflect.Value) (string, error) {
+	var size float64
+	var unit string
+	switch v.Kind() {
+	case reflect.String:
+		size, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		size, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = v.Float()
+	default:
+		return "", fmt.Errorf("%q doesn't apply to %s", rule, v.Type())
+	}
+	if unit != "" && size == 0 {
+		return "", nil
+	}
+	switch {
+	case rule == "min" && size < n:
+		return "must be at least " + arg + unit, nil
+	case rule == "max" && size > n:
+		return "must be at most " + arg + unit, nil
+	case rule == "len" && size != n:
+		return "must be exactly " + arg + unit, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,88 @@
+package request_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/internal/is"
+)
+
+func TestValidateOK(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Name  string `json:"name" validate:"required,max=5"`
+		Email string `json:"email" validate:"email"`
+		Age   int    `validate:"min=18"`
+	}
+	is.NoErr(Validate(&S{Name: "a", Email: "a@b.com", Age: 18}))
+}
+
+func TestValidateFields(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Name  string  `json:"name" validate:"required"`
+		Email string  `json:"email" validate:"required,email,max=8"`
+		Age   int     `validate:"min=18"`
+		Role  string  `json:"role" validate:"oneof=admin member"`
+		Bio   *string `json:"bio" validate:"required"`
+	}
+	err := Validate(&S{Email: "not-an-email", Age: 10, Role: "owner"})
+	is.True(err != nil)
+	verr := new(ValidationError)
+	is.True(errors.As(err, &verr))
+	is.Equal(len(verr.Fields), 5)
+	is.Equal(verr.Fields["name"], []string{"is required"})
+	is.Equal(verr.Fields["email"], []string{"must be a valid email address", "must be at most 8 characters"})
+	is.Equal(verr.Fields["Age"], []string{"must be at least 18"})
+	is.Equal(verr.Fields["role"], []string{"must be one of admin, member"})
+	is.Equal(verr.Fields["bio"], []string{"is required"})
+}
+
+func TestValidateEmptyOptional(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Email string   `validate:"email,min=3"`
+		Tags  []string `validate:"max=2"`
+		Bio   *string  `validate:"max=2"`
+	}
+	is.NoErr(Validate(&S{}))
+}
+
+func TestValidateNested(t *testing.T) {
+	is := is.New(t)
+	type Address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type S struct {
+		Address *Address `json:"address"`
+	}
+	err := Validate(&S{Address: &Address{}})
+	verr := new(ValidationError)
+	is.True(errors.As(err, &verr))
+	is.Equal(verr.Fields["address.city"], []string{"is required"})
+}
+
+func TestValidateUnknownRule(t *testing.T) {
+	is := is.New(t)
+	type S struct {
+		Name string `validate:"uppercase"`
+	}
+	err := Validate(&S{})
+	is.True(err != nil)
+	is.Equal(err.Error(), `request: invalid validate tag on S.Name. unknown rule "uppercase"`)
+}
+
+func TestCheckTag(t *testing.T) {
+	is := is.New(t)
+	is.NoErr(CheckTag("required, email,max=80,oneof=a b"))
+	err := CheckTag("required,uppercase")
+	is.True(err != nil)
+	is.Equal(err.Error(), `unknown rule "uppercase"`)
+	err = CheckTag("min=abc")
+	is.True(err != nil)
+	is.Equal(err.Error(), `"min" expects a number, got "abc"`)
+	err = CheckTag("oneof=")
+	is.True(err != nil)
+	is.Equal(err.Error(), `"oneof" expects at least one option`)
+}
@@ -0,0 +1,122 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Sentinel errors that map to their HTTP status codes. Wrap them to add
+// context, e.g. fmt.Errorf("post %d: %w", id, response.ErrNotFound).
+var (
+	ErrNotFound     error = &statusError{http.StatusNotFound, "not found"}
+	ErrUnauthorized error = &statusError{http.StatusUnauthorized, "unauthorized"}
+	ErrForbidden    error = &statusError{http.StatusForbidden, "forbidden"}
+	ErrConflict     error = &statusError{http.StatusConflict, "conflict"}
+)
+
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+func (e *statusError) StatusCode() int {
+	return e.status
+}
+
+// WithStatus wraps err with a status code. The error message stays the same.
+func WithStatus(status int, err error) error {
+	return &wrapError{status, err}
+}
+
+type wrapError struct {
+	status int
+	err    error
+}
+
+func (e *wrapError) Error() string {
+	return e.err.Error()
+}
+
+func (e *wrapError) StatusCode() int {
+	return e.status
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// StatusCoder is implemented by errors that map to an HTTP status code
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// StatusCode returns the status code of the first error in err's chain that
+// implements StatusCoder. It returns 0 if there is none.
+func StatusCode(err error) int {
+	var coder StatusCoder
+	if !errors.As(err, &coder) {
+		return 0
+	}
+	status := coder.StatusCode()
+	if status < 400 || status > 599 {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+// ErrorStatus returns the status code of the error, or a 500 if it doesn't
+// have one
+func ErrorStatus(err error) int {
+	if status := StatusCode(err); status != 0 {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// problem details as described in RFC 7807. Fields is an extension member
+// that lists the invalid fields of validation errors.
+type problem struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail,omitempty"`
+	Fields map[string][]string `json:"fields,omitempty"`
+}
+
+// Problem responds with an application/problem+json error using the error's
+// status code, or a 500 if it doesn't have one.
+func Problem(err error) http.Handler {
+	status := ErrorStatus(err)
+	res := Status(status).JSON(&problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
+		Fields: Fields(err),
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res.ServeHTTP(&problemWriter{w}, r)
+	})
+}
+
+// problemWriter overrides the JSON content type right before it's written
+type problemWriter struct {
+	http.ResponseWriter
+}
+
+func (w *problemWriter) WriteHeader(status int) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// ErrorHTML responds with a plain text error using the error's status code, or
+// a 500 if it doesn't have one. It's used when there's no error view.
+func ErrorHTML(err error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, err.Error(), ErrorStatus(err))
+	})
+}
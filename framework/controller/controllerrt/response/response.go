@@ -2,10 +2,12 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
 
+	"github.com/felixge/httpsnoop"
 	"github.com/livebud/bud/framework/controller/controllerrt/request"
 )
 
@@ -174,6 +176,44 @@ func (res *Response) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(res.status)
 }
 
+// Wrap the handler, overriding the status code that it writes
+func (res *Response) Wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Attach all preset headers
+		header := w.Header()
+		for key, value := range res.headers {
+			header.Set(key, value)
+		}
+		if res.status == 0 {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(writeHeader httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(int) { writeHeader(res.status) }
+			},
+		}), r)
+	})
+}
+
+// Fields returns the invalid fields if err is a validation error
+func Fields(err error) map[string][]string {
+	verr := new(request.ValidationError)
+	if !errors.As(err, &verr) {
+		return nil
+	}
+	return verr.Fields
+}
+
+// InvalidHTML re-renders the form with 422 Unprocessable Entity when err is a
+// validation error. Other errors respond like ErrorHTML.
+func InvalidHTML(err error, form http.Handler) http.Handler {
+	if Fields(err) == nil {
+		return ErrorHTML(err)
+	}
+	return Status(http.StatusUnprocessableEntity).Wrap(form)
+}
+
 // RedirectPath returns the response path.
 func RedirectPath(r *http.Request, subpath string) string {
 	switch r.Method {
@@ -0,0 +1,137 @@
+package response
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/livebud/bud/package/hot"
+)
+
+// Events streams the values received from the channel as server-sent events,
+// encoding each value as JSON. The stream ends when the channel is closed or
+// when the request is cancelled.
+func Events[T any](ch <-chan T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := startEvents(w)
+		if !ok {
+			return
+		}
+		ctx := r.Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case value, ok := <-ch:
+				if !ok {
+					return
+				}
+				if err := writeEvent(w, value); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+// Iterate streams the values yielded by the iterator as server-sent events,
+// encoding each value as JSON. Yield returns false once the request is
+// cancelled, so the iterator can stop early.
+func Iterate[T any](seq func(yield func(T) bool)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if seq == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		flusher, ok := startEvents(w)
+		if !ok {
+			return
+		}
+		ctx := r.Context()
+		seq(func(value T) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			if err := writeEvent(w, value); err != nil {
+				return false
+			}
+			flusher.Flush()
+			return true
+		})
+	})
+}
+
+// startEvents sets the event stream headers and flushes them
+func startEvents(w http.ResponseWriter) (http.Flusher, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "response: unable to stream because the response writer is not a flusher", http.StatusInternalServerError)
+		return nil, false
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return flusher, true
+}
+
+func writeEvent(w io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	event := &hot.Event{Data: data}
+	_, err = event.Format().WriteTo(w)
+	return err
+}
+
+// Stream copies the reader to the response in chunks, flushing after each
+// chunk. The content type is sniffed from the start of the stream unless it's
+// already been set. The reader is closed afterwards if it's an io.Closer.
+func Stream(reader io.Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reader == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		buffered := bufio.NewReader(reader)
+		header := w.Header()
+		if header.Get("Content-Type") == "" {
+			// Peek returns an error when there's less than 512 bytes, which is fine
+			sniff, _ := buffered.Peek(512)
+			header.Set("Content-Type", http.DetectContentType(sniff))
+		}
+		flusher, _ := w.(http.Flusher)
+		ctx := r.Context()
+		buf := make([]byte, 32*1024)
+		wrote := false
+		for ctx.Err() == nil {
+			n, err := buffered.Read(buf)
+			if n > 0 {
+				wrote = true
+				if _, err := w.Write(buf[:n]); err != nil {
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				// The status has already been sent once we've written
+				if !wrote {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package response_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/framework/controller/controllerrt/response"
+	"github.com/livebud/bud/internal/is"
+)
+
+type progress struct {
+	Percent int `json:"percent"`
+}
+
+func TestEvents(t *testing.T) {
+	is := is.New(t)
+	ch := make(chan *progress, 2)
+	ch <- &progress{50}
+	ch <- &progress{100}
+	close(ch)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	response.Events(ch).ServeHTTP(rec, req)
+	is.Equal(rec.Code, 200)
+	is.Equal(rec.Header().Get("Content-Type"), "text/event-stream")
+	is.Equal(rec.Header().Get("Cache-Control"), "no-cache")
+	is.Equal(rec.Body.String(), "data: {\"percent\":50}\n\ndata: {\"percent\":100}\n\n")
+	is.True(rec.Flushed)
+}
+
+func TestEventsCancel(t *testing.T) {
+	is := is.New(t)
+	ch := make(chan int)
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	done := make(chan struct{})
+	go func() {
+		response.Events(ch).ServeHTTP(rec, req)
+		close(done)
+	}()
+	ch <- 1
+	cancel()
+	<-done
+	is.Equal(rec.Body.String(), "data: 1\n\n")
+}
+
+func TestIterate(t *testing.T) {
+	is := is.New(t)
+	seq := func(yield func(string) bool) {
+		for _, value := range []string{"a", "b"} {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	response.Iterate(seq).ServeHTTP(rec, req)
+	is.Equal(rec.Header().Get("Content-Type"), "text/event-stream")
+	is.Equal(rec.Body.String(), "data: \"a\"\n\ndata: \"b\"\n\n")
+}
+
+func TestIterateCancel(t *testing.T) {
+	is := is.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := false
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if i == 2 {
+				cancel()
+			}
+			if !yield(i) {
+				stopped = true
+				return
+			}
+		}
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	response.Iterate(seq).ServeHTTP(rec, req)
+	is.True(stopped)
+	is.Equal(rec.Body.String(), "data: 0\n\ndata: 1\n\n")
+}
+
+type readCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (r *readCloser) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestStream(t *testing.T) {
+	is := is.New(t)
+	reader := &readCloser{Reader: strings.NewReader("id,name\n1,alice\n")}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	response.Stream(reader).ServeHTTP(rec, req)
+	is.Equal(rec.Code, 200)
+	is.Equal(rec.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+	is.Equal(rec.Body.String(), "id,name\n1,alice\n")
+	is.True(reader.closed)
+}
+
+func TestStreamContentType(t *testing.T) {
+	is := is.New(t)
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "text/csv")
+	req := httptest.NewRequest("GET", "/", nil)
+	response.Stream(strings.NewReader("id\n1\n")).ServeHTTP(rec, req)
+	is.Equal(rec.Header().Get("Content-Type"), "text/csv")
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("unable to read")
+}
+
+func TestStreamError(t *testing.T) {
+	is := is.New(t)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	response.Stream(errReader{}).ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusInternalServerError)
+	is.In(rec.Body.String(), "unable to read")
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
 	"github.com/livebud/bud/internal/gois"
 	"github.com/livebud/bud/package/valid"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/livebud/bud/package/gomod"
 	"github.com/livebud/bud/package/imports"
 	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/router/radix"
 	"github.com/matthewmueller/gotext"
 	"github.com/matthewmueller/text"
 )
@@ -31,6 +34,7 @@ func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parse
 		fsys:      fsys,
 		providers: newProviderSet(),
 		imports:   imports.New(),
+		routes:    newRouteSet(),
 		injector:  injector,
 		module:    module,
 		parser:    parser,
@@ -45,6 +49,7 @@ type loader struct {
 	injector  *di.Injector
 	imports   *imports.Set
 	providers *providerSet
+	routes    *routeSet
 	module    *gomod.Module
 	parser    *parser.Parser
 }
@@ -56,6 +61,7 @@ func (l *loader) Load() (state *State, err error) {
 	state.Controller = l.loadController("controller")
 	state.Providers = l.providers.List()
 	l.imports.AddNamed("router", "github.com/livebud/bud/package/router")
+	l.imports.AddNamed("middleware", "github.com/livebud/bud/package/middleware")
 	state.Imports = l.imports.List()
 	return state, nil
 }
@@ -133,25 +139,28 @@ func (l *loader) loadControllerRoute(controllerPath string) string {
 }
 
 func (l *loader) loadActions(controller *Controller, stct *parser.Struct) (actions []*Action) {
-	var usesResponse bool
+	var methods, middleware []*parser.Function
 	for _, method := range stct.PublicMethods() {
-		action := l.loadAction(controller, method)
-		if !action.HandlerFunc {
-			usesResponse = true
+		if l.isMiddleware(method) {
+			middleware = append(middleware, method)
+			continue
 		}
-		actions = append(actions, action)
+		methods = append(methods, method)
+	}
+	controller.Middleware = l.loadMiddleware(controller, middleware)
+	for _, method := range methods {
+		actions = append(actions, l.loadAction(controller, method))
 	}
 	// Add the imports if we have more than one action
-	if len(actions) > 0 {
+	if len(actions) > 0 || controller.Middleware != nil {
 		importPath, err := stct.File().Import()
 		if err != nil {
 			l.Bail(err)
 		}
 		l.imports.Add(importPath)
 		l.imports.Add("net/http")
-		if usesResponse {
-			l.imports.Add("github.com/livebud/bud/framework/controller/controllerrt/response")
-		}
+		// Actions and middleware respond with errors
+		l.imports.Add("github.com/livebud/bud/framework/controller/controllerrt/response")
 	}
 	return actions
 }
@@ -164,23 +173,98 @@ func (l *loader) loadAction(controller *Controller, method *parser.Function) *Ac
 	action.Short = text.Lower(gotext.Short(action.Name))
 	action.Route = l.loadActionRoute(controller.Route, action.Name)
 	action.Key = l.loadActionKey(controller.Path, action.Name)
-	action.View = l.loadView(controller.Path, action.Key, action.Route)
 	action.Method = l.loadActionMethod(action.Name)
+	l.loadActionDirectives(action, controller.Route, method)
+	action.View = l.loadView(controller.Path, action.Key, action.Route)
+	if action.View != nil && (action.Name == "New" || action.Name == "Edit") {
+		action.View.Form = true
+	}
+	action.FormView = l.loadFormView(controller, action.Name)
+	action.ErrorView = l.loadErrorView(controller, action.View)
+	if err := l.routes.Add(action); err != nil {
+		l.Bail(err)
+	}
 	params := method.Params()
 	results := method.Results()
 	action.HandlerFunc = l.isHandlerFunc(params, results)
 	if !action.HandlerFunc {
-		action.Params = l.loadActionParams(params)
+		action.Params = l.loadActionParams(params, l.loadValidateDirectives(action, method))
 		action.Input = l.loadActionInput(action.Params)
+		action.Upload = l.loadUploadDirective(action, method)
 		action.Results = l.loadActionResults(results)
+		action.Socket = l.loadActionSocket(action)
 	}
 	action.RespondJSON = len(action.Results) > 0
 	action.RespondHTML = l.loadRespondHTML(action.Results)
 	action.Provider = l.loadProvider(controller, method)
 	action.Redirect = l.loadActionRedirect(action)
+	action.Middleware = l.loadMiddlewareDirective(controller, action, method)
 	return action
 }
 
+// isMiddleware returns true if the method has the middleware signature:
+// func(next http.Handler) http.Handler
+func (l *loader) isMiddleware(method *parser.Function) bool {
+	params := method.Params()
+	results := method.Results()
+	if len(params) == 1 && len(results) == 1 && l.isHandler(params[0].Type()) && l.isHandler(results[0].Type()) {
+		return true
+	}
+	// Middleware is reserved for wrapping all the actions in the controller
+	if method.Name() == "Middleware" {
+		l.Bail(fmt.Errorf("controller: expected Middleware to have the signature func(next http.Handler) http.Handler"))
+	}
+	return false
+}
+
+func (l *loader) isHandler(dt parser.Type) bool {
+	isHandler, err := parser.IsImportType(dt, "net/http", "Handler")
+	if err != nil {
+		l.Bail(err)
+	}
+	return isHandler
+}
+
+// loadMiddleware loads the middleware methods of the controller
+func (l *loader) loadMiddleware(controller *Controller, methods []*parser.Function) *Middleware {
+	if len(methods) == 0 {
+		return nil
+	}
+	middleware := new(Middleware)
+	for _, method := range methods {
+		name := method.Name()
+		middleware.Methods = append(middleware.Methods, name)
+		if name == "Middleware" {
+			middleware.All = true
+		}
+	}
+	middleware.Provider = l.loadProvider(controller, methods[0])
+	return middleware
+}
+
+const middlewareDirective = "bud:middleware"
+
+// loadMiddlewareDirective loads the "//bud:middleware [METHOD...]" comments
+// above the action. These middleware methods only wrap this action.
+func (l *loader) loadMiddlewareDirective(controller *Controller, action *Action, method *parser.Function) (names []string) {
+	for _, directive := range method.Directives() {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 || fields[0] != middlewareDirective {
+			continue
+		}
+		if len(fields) == 1 {
+			l.Bail(fmt.Errorf("controller: invalid directive %q on %s, expected \"//%s [METHOD...]\"", directive, action.Key, middlewareDirective))
+		}
+		for _, name := range fields[1:] {
+			if !controller.Middleware.Has(name) || name == "Middleware" {
+				l.Bail(fmt.Errorf("controller: unknown middleware %q in %q directive on %s", name, middlewareDirective, action.Key))
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (l *loader) loadActionKey(controllerPath, actionName string) string {
 	return path.Join(controllerPath, text.Lower(text.Snake(actionName)))
 }
@@ -232,6 +316,7 @@ func (l *loader) loadActionRoute(controllerRoute, actionName string) string {
 const (
 	methodGet    = "Get"
 	methodPost   = "Post"
+	methodPut    = "Put"
 	methodPatch  = "Patch"
 	methodDelete = "Delete"
 )
@@ -250,6 +335,195 @@ func (l *loader) loadActionMethod(actionName string) string {
 	}
 }
 
+const routeDirective = "bud:route"
+
+// loadActionDirectives overrides the RESTful route and method with a
+// "//bud:route [METHOD] [ROUTE]" comment above the action. Relative routes are
+// joined with the controller's route.
+func (l *loader) loadActionDirectives(action *Action, controllerRoute string, method *parser.Function) {
+	for _, directive := range method.Directives() {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 || fields[0] != routeDirective {
+			continue
+		}
+		args := fields[1:]
+		if len(args) == 0 || len(args) > 2 {
+			l.Bail(fmt.Errorf("controller: invalid directive %q on %s, expected \"//%s [METHOD] [ROUTE]\"", directive, action.Key, routeDirective))
+		}
+		httpMethod, ok := toMethod(args[0])
+		if !ok {
+			l.Bail(fmt.Errorf("controller: invalid method %q in %q on %s", args[0], directive, action.Key))
+		}
+		action.Method = httpMethod
+		if len(args) == 1 {
+			continue
+		}
+		action.Route = joinRoute(controllerRoute, args[1])
+	}
+}
+
+// toMethod converts an HTTP method (e.g. "POST") into the router method
+func toMethod(method string) (string, bool) {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return methodGet, true
+	case "POST":
+		return methodPost, true
+	case "PUT":
+		return methodPut, true
+	case "PATCH":
+		return methodPatch, true
+	case "DELETE":
+		return methodDelete, true
+	default:
+		return "", false
+	}
+}
+
+// joinRoute joins a relative route to the controller route. Absolute routes
+// are returned as-is.
+func joinRoute(controllerRoute, route string) string {
+	if strings.HasPrefix(route, "/") {
+		return route
+	}
+	return path.Join(controllerRoute, route)
+}
+
+const validateDirective = "bud:validate"
+
+// loadValidateDirectives loads the "//bud:validate [PARAM] [RULES]" comments
+// above the action. These are added as validate tags on the input struct.
+func (l *loader) loadValidateDirectives(action *Action, method *parser.Function) map[string]string {
+	rules := map[string]string{}
+	for _, directive := range method.Directives() {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 || fields[0] != validateDirective {
+			continue
+		}
+		if len(fields) < 3 {
+			l.Bail(fmt.Errorf("controller: invalid directive %q on %s, expected \"//%s [PARAM] [RULES]\"", directive, action.Key, validateDirective))
+		}
+		rule := strings.Join(fields[2:], " ")
+		// Rules are written into a raw string struct tag, which can't contain
+		// backticks
+		if strings.Contains(rule, "`") {
+			l.Bail(fmt.Errorf("controller: invalid rules %q in %q directive on %s, rules can't contain backticks", rule, validateDirective, action.Key))
+		}
+		if err := request.CheckTag(rule); err != nil {
+			l.Bail(fmt.Errorf("controller: invalid rules %q in %q directive on %s. %w", rule, validateDirective, action.Key, err))
+		}
+		rules[fields[1]] = rule
+	}
+	for name := range rules {
+		found := false
+		for _, param := range method.Params() {
+			if param.Name() == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l.Bail(fmt.Errorf("controller: unknown param %q in %q directive on %s", name, validateDirective, action.Key))
+		}
+	}
+	return rules
+}
+
+const uploadDirective = "bud:upload"
+
+// loadUploadDirective loads the "//bud:upload max=[SIZE] memory=[SIZE]"
+// comment above the action to configure the multipart limits.
+func (l *loader) loadUploadDirective(action *Action, method *parser.Function) *Upload {
+	for _, directive := range method.Directives() {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 || fields[0] != uploadDirective {
+			continue
+		}
+		upload := new(Upload)
+		for _, arg := range fields[1:] {
+			key, value, _ := strings.Cut(arg, "=")
+			size, err := parseSize(value)
+			if err != nil {
+				l.Bail(fmt.Errorf("controller: invalid size in %q on %s. %w", directive, action.Key, err))
+			}
+			switch key {
+			case "max":
+				upload.MaxSize = size
+			case "memory":
+				upload.MaxMemory = size
+			default:
+				l.Bail(fmt.Errorf("controller: unknown option %q in %q on %s", key, directive, action.Key))
+			}
+		}
+		return upload
+	}
+	return nil
+}
+
+// parseSize parses sizes like "512", "10KB", "10MB" and "1GB" into bytes
+func parseSize(size string) (int64, error) {
+	units := []struct {
+		suffix string
+		scale  int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	upper := strings.ToUpper(size)
+	scale := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(upper, unit.suffix) {
+			upper = strings.TrimSuffix(upper, unit.suffix)
+			scale = unit.scale
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a size like \"10MB\", got %q", size)
+	}
+	return n * scale, nil
+}
+
+// loadFormView loads the view that submits to the action. This view is
+// re-rendered with the submitted input when validation fails.
+func (l *loader) loadFormView(controller *Controller, actionName string) *View {
+	var formAction string
+	switch actionName {
+	case "Create":
+		formAction = "New"
+	case "Update":
+		formAction = "Edit"
+	default:
+		return nil
+	}
+	formKey := l.loadActionKey(controller.Path, formAction)
+	formRoute := l.loadActionRoute(controller.Route, formAction)
+	view := l.loadView(controller.Path, formKey, formRoute)
+	if view != nil {
+		view.Form = true
+	}
+	return view
+}
+
+// loadErrorView loads a view within the controller's view directory. Rendering
+// this view with an error renders the nearest error page instead.
+func (l *loader) loadErrorView(controller *Controller, view *View) *View {
+	if view != nil {
+		return view
+	}
+	for _, actionName := range []string{"Index", "Show", "New", "Edit"} {
+		key := l.loadActionKey(controller.Path, actionName)
+		route := l.loadActionRoute(controller.Route, actionName)
+		if view := l.loadView(controller.Path, key, route); view != nil {
+			return view
+		}
+	}
+	return nil
+}
+
 func (l *loader) loadView(controllerKey, actionKey, actionRoute string) *View {
 	viewDir := path.Join("view", controllerKey)
 	des, err := fs.ReadDir(l.fsys, viewDir)
@@ -272,16 +546,17 @@ func (l *loader) loadView(controllerKey, actionKey, actionRoute string) *View {
 		}
 		l.imports.Add(l.module.Import("bud/internal/web/view"))
 		return &View{
+			Path:  path.Join(viewDir, name),
 			Route: actionRoute,
 		}
 	}
 	return nil
 }
 
-func (l *loader) loadActionParams(params []*parser.Param) (inputs []*ActionParam) {
+func (l *loader) loadActionParams(params []*parser.Param, rules map[string]string) (inputs []*ActionParam) {
 	numParams := len(params)
 	for nth, param := range params {
-		inputs = append(inputs, l.loadActionParam(param, nth, numParams))
+		inputs = append(inputs, l.loadActionParam(param, nth, numParams, rules[param.Name()]))
 	}
 	if len(inputs) > 0 {
 		l.imports.Add("github.com/livebud/bud/framework/controller/controllerrt/request")
@@ -289,7 +564,7 @@ func (l *loader) loadActionParams(params []*parser.Param) (inputs []*ActionParam
 	return inputs
 }
 
-func (l *loader) loadActionParam(param *parser.Param, nth, numParams int) *ActionParam {
+func (l *loader) loadActionParam(param *parser.Param, nth, numParams int, rules string) *ActionParam {
 	dec, err := param.Definition()
 	if err != nil {
 		l.Bail(fmt.Errorf("controller: unable to find param definition for %s. %w", param.Type(), err))
@@ -299,11 +574,19 @@ func (l *loader) loadActionParam(param *parser.Param, nth, numParams int) *Actio
 	ap.Pascal = gotext.Pascal(ap.Name)
 	ap.Snake = gotext.Lower(gotext.Snake(ap.Name))
 	ap.Type = l.loadType(param.Type(), dec)
-	ap.Tag = fmt.Sprintf("`json:\"%[1]s\"`", tagValue(ap.Snake))
+	ap.Tag = loadTag(ap.Snake, rules)
 	ap.Kind = string(dec.Kind())
+	ap.Upload = l.isUpload(dec)
+	ap.Socket = l.isSocket(dec)
+	if dec.Kind() == parser.KindStruct && !ap.Upload && !ap.Socket {
+		l.checkValidateTags(dec, map[string]bool{})
+	}
 	switch {
+	// Handle WebSocket connections
+	case ap.Socket:
+		ap.Variable = "conn"
 	// Single struct input
-	case numParams == 1 && dec.Kind() == parser.KindStruct:
+	case numParams == 1 && dec.Kind() == parser.KindStruct && !ap.Upload:
 		ap.Variable = "in"
 	// Handle context.Context
 	case ap.IsContext():
@@ -314,6 +597,116 @@ func (l *loader) loadActionParam(param *parser.Param, nth, numParams int) *Actio
 	return ap
 }
 
+// checkValidateTags checks the validate tags of the input struct and the
+// structs within it, so invalid rules fail the build instead of every request.
+// Only structs within the app are checked.
+func (l *loader) checkValidateTags(dec parser.Declaration, seen map[string]bool) {
+	importPath, err := dec.Package().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	key := importPath + "." + dec.Name()
+	if seen[key] || !l.module.IsLocal(importPath) {
+		return
+	}
+	seen[key] = true
+	stct := dec.Package().Struct(dec.Name())
+	if stct == nil {
+		return
+	}
+	for _, field := range stct.PublicFields() {
+		tags, err := field.Tags()
+		if err != nil {
+			l.Bail(err)
+		}
+		for _, tag := range tags {
+			if tag.Key != "validate" || tag.Value == "-" {
+				continue
+			}
+			rules := strings.Join(append([]string{tag.Value}, tag.Options...), ",")
+			if err := request.CheckTag(rules); err != nil {
+				l.Bail(fmt.Errorf("controller: invalid validate tag on %s.%s. %w", stct.Name(), field.Name(), err))
+			}
+		}
+		// Validate only recurses into structs and pointers to structs
+		ft := field.Type()
+		if star, ok := ft.(*parser.StarType); ok {
+			ft = star.Inner()
+		}
+		switch t := ft.(type) {
+		case *parser.IdentType:
+			if parser.IsBuiltin(t) {
+				continue
+			}
+		case *parser.SelectorType:
+			importPath, err := t.ImportPath()
+			if err != nil || !l.module.IsLocal(importPath) {
+				continue
+			}
+		default:
+			continue
+		}
+		def, err := parser.Definition(ft)
+		if err != nil || def.Kind() != parser.KindStruct {
+			continue
+		}
+		l.checkValidateTags(def, seen)
+	}
+}
+
+const uploadImport = "github.com/livebud/bud/package/upload"
+
+// isUpload returns true if the param is an uploaded file
+func (l *loader) isUpload(dec parser.Declaration) bool {
+	if dec.Kind() != parser.KindStruct || dec.Name() != "File" {
+		return false
+	}
+	importPath, err := dec.Package().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	return importPath == uploadImport
+}
+
+const socketImport = "github.com/livebud/bud/package/websocket"
+
+// isSocket returns true if the param is a WebSocket connection
+func (l *loader) isSocket(dec parser.Declaration) bool {
+	if dec.Kind() != parser.KindStruct || dec.Name() != "Conn" {
+		return false
+	}
+	importPath, err := dec.Package().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	return importPath == socketImport
+}
+
+// loadActionSocket returns true if the action accepts a WebSocket connection.
+// The connection is upgraded from a GET request and there's no response to
+// write results to, so sockets may only return an error.
+func (l *loader) loadActionSocket(action *Action) bool {
+	sockets := 0
+	for _, param := range action.Params {
+		if param.Socket {
+			sockets++
+		}
+	}
+	if sockets == 0 {
+		return false
+	} else if sockets > 1 {
+		l.Bail(fmt.Errorf("controller: %s can only accept one websocket connection", action.Key))
+	}
+	if action.Method != methodGet {
+		l.Bail(fmt.Errorf("controller: websocket action %s must be a GET request, not %s", action.Key, strings.ToUpper(action.Method)))
+	}
+	if action.Results.Result() != "" {
+		l.Bail(fmt.Errorf("controller: websocket action %s can only return an error", action.Key))
+	}
+	l.imports.Add(socketImport)
+	return true
+}
+
 func (l *loader) loadActionParamName(param *parser.Param, nth int) string {
 	name := param.Name()
 	if name != "" {
@@ -345,7 +738,7 @@ func (l *loader) loadType(dt parser.Type, dec parser.Declaration) string {
 }
 
 func (l *loader) loadActionInput(params []*ActionParam) string {
-	if len(params) == 1 && params[0].Kind == string(parser.KindStruct) {
+	if len(params) == 1 && params[0].Kind == string(parser.KindStruct) && !params[0].Upload && !params[0].Socket {
 		return params[0].Type
 	}
 	return l.loadActionInputStruct(params)
@@ -355,7 +748,7 @@ func (l *loader) loadActionInputStruct(params []*ActionParam) string {
 	b := new(strings.Builder)
 	b.WriteString("struct {")
 	for _, param := range params {
-		if param.IsContext() {
+		if param.IsContext() || param.Socket {
 			continue
 		}
 		b.WriteString("\n")
@@ -369,32 +762,82 @@ func (l *loader) loadActionInputStruct(params []*ActionParam) string {
 	return b.String()
 }
 
-func (l *loader) loadActionResults(results []*parser.Result) (outputs []*ActionResult) {
+func (l *loader) loadActionResults(results []*parser.Result) (outputs ActionResults) {
 	for order, result := range results {
 		outputs = append(outputs, l.loadActionResult(order, result))
 	}
+	// Streams are written as they're produced, so they can't be combined with
+	// other results
+	if outputs.Stream() != "" && outputs.Result() != outputs.StreamResult() {
+		l.Bail(fmt.Errorf("controller: streamed results can only be returned with an error"))
+	}
 	return outputs
 }
 
 func (l *loader) loadActionResult(order int, result *parser.Result) *ActionResult {
-	def, err := result.Definition()
-	if err != nil {
-		l.Bail(fmt.Errorf("controller: unable to load result definition for %s. %w", result.Type(), err))
-	}
 	output := new(ActionResult)
 	output.Name = l.loadActionResultName(order, result)
 	output.Pascal = gotext.Pascal(output.Name)
 	output.Named = result.Named()
 	output.Snake = gotext.Snake(output.Name)
 	output.Type = parser.Unqualify(result.Type()).String()
-	output.Kind = def.Kind()
 	output.Variable = l.loadActionResultVariable(order, result)
+	// Channels and iterators don't have a definition
+	if output.Stream = l.loadResultStream(result.Type()); output.Stream != "" {
+		return output
+	}
+	def, err := result.Definition()
+	if err != nil {
+		l.Bail(fmt.Errorf("controller: unable to load result definition for %s. %w", result.Type(), err))
+	}
+	output.Kind = def.Kind()
+	output.Stream = l.loadReaderStream(def)
 	output.Fields = l.loadActionResultFields(result, def)
 	// TODO: check for other types that implement error
 	output.IsError = output.Type == "error"
 	return output
 }
 
+// iterator matches functions like func(yield func(T) bool)
+var iterator = regexp.MustCompile(`^func\((\w+ )?func\([^()]+\) bool\)$`)
+
+// loadResultStream returns the response function that streams channels and
+// iterators
+func (l *loader) loadResultStream(dt parser.Type) string {
+	switch dt.(type) {
+	case *parser.ChanType:
+		if strings.HasPrefix(dt.String(), "chan<-") {
+			l.Bail(fmt.Errorf("controller: unable to stream the send-only channel %s", dt))
+		}
+		return "Events"
+	case *parser.FuncType:
+		if !iterator.MatchString(dt.String()) {
+			l.Bail(fmt.Errorf("controller: unable to return %s. Functions must be iterators like func(yield func(T) bool)", dt))
+		}
+		return "Iterate"
+	default:
+		return ""
+	}
+}
+
+// loadReaderStream returns the response function that streams readers
+func (l *loader) loadReaderStream(def parser.Declaration) string {
+	if def.Kind() != parser.KindInterface {
+		return ""
+	}
+	if name := def.Name(); name != "Reader" && name != "ReadCloser" {
+		return ""
+	}
+	importPath, err := def.Package().Import()
+	if err != nil {
+		l.Bail(err)
+	}
+	if importPath != "io" {
+		return ""
+	}
+	return "Stream"
+}
+
 func (l *loader) loadActionResultName(order int, result *parser.Result) string {
 	name := result.Name()
 	if name != "" {
@@ -551,6 +994,34 @@ func (l *loader) loadProvider(controller *Controller, method *parser.Function) *
 	return provider
 }
 
+func newRouteSet() *routeSet {
+	return &routeSet{map[string]radix.Tree{}, map[string]string{}}
+}
+
+// routeSet detects conflicting routes across all the controllers at generate
+// time, rather than when the router is being built at runtime.
+type routeSet struct {
+	trees map[string]radix.Tree
+	keys  map[string]string
+}
+
+func (r *routeSet) Add(action *Action) error {
+	id := strings.ToUpper(action.Method) + " " + action.Route
+	if key, ok := r.keys[id]; ok {
+		return fmt.Errorf("controller: route %q conflicts between %s and %s", id, key, action.Key)
+	}
+	r.keys[id] = action.Key
+	tree, ok := r.trees[action.Method]
+	if !ok {
+		tree = radix.New()
+		r.trees[action.Method] = tree
+	}
+	if err := tree.Insert(action.Route, nil); err != nil {
+		return fmt.Errorf("controller: unable to add route for %s. %w", action.Key, err)
+	}
+	return nil
+}
+
 func newProviderSet() *providerSet {
 	return &providerSet{map[string]*di.Provider{}}
 }
@@ -573,6 +1044,13 @@ func (c *providerSet) List() (providers []*di.Provider) {
 	return providers
 }
 
+func loadTag(snake, rules string) string {
+	if rules == "" {
+		return fmt.Sprintf("`json:\"%[1]s\"`", tagValue(snake))
+	}
+	return fmt.Sprintf("`json:\"%[1]s\" validate:%[2]s`", tagValue(snake), strconv.Quote(rules))
+}
+
 func tagValue(snake string) (out string) {
 	if snake == "" {
 		out += "-"
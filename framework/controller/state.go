@@ -24,10 +24,31 @@ type Controller struct {
 	JSON        string
 	Path        string // Path to controller without action dir
 	Route       string
+	Middleware  *Middleware
 	Actions     []*Action
 	Controllers []*Controller
 }
 
+// Middleware wraps the controller's actions
+type Middleware struct {
+	Provider *di.Provider
+	Methods  []string // Methods with the func(next http.Handler) http.Handler signature
+	All      bool     // All is true if the Middleware method wraps every action
+}
+
+// Has returns true if the controller has the middleware method
+func (m *Middleware) Has(name string) bool {
+	if m == nil {
+		return false
+	}
+	for _, method := range m.Methods {
+		if method == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Controller) Last() Name {
 	names := strings.Split(c.Name, " ")
 	return Name(names[len(names)-1])
@@ -46,6 +67,8 @@ type Action struct {
 	Camel       string
 	Short       string
 	View        *View
+	FormView    *View  // View to re-render when validation fails
+	ErrorView   *View  // View whose nearest error page renders failures
 	Key         string // Key is an extension-less path
 	Route       string // Route to this action
 	Redirect    string
@@ -53,7 +76,10 @@ type Action struct {
 	Provider    *di.Provider
 	Params      []*ActionParam
 	HandlerFunc bool
+	Socket      bool // Socket is true for WebSocket actions
 	Input       string
+	Upload      *Upload
+	Middleware  []string // Middleware that only wraps this action
 	Results     ActionResults
 	RespondJSON bool
 	RespondHTML bool
@@ -62,7 +88,15 @@ type Action struct {
 
 // View struct
 type View struct {
+	Path  string // Path to the view page, e.g. view/posts/show.svelte
 	Route string
+	Form  bool // Form views submit a form, so they receive a CSRF token
+}
+
+// Upload configures the multipart limits of an action
+type Upload struct {
+	MaxSize   int64
+	MaxMemory int64
 }
 
 // ActionParam struct
@@ -74,6 +108,8 @@ type ActionParam struct {
 	Kind     string
 	Variable string
 	Tag      string
+	Upload   bool // Upload is true for uploaded files
+	Socket   bool // Socket is true for WebSocket connections
 }
 
 func (ap *ActionParam) IsContext() bool {
@@ -190,6 +226,26 @@ func (results ActionResults) isObject() bool {
 	return true
 }
 
+// Stream returns the response function that streams the result, if any
+func (results ActionResults) Stream() string {
+	for _, result := range results {
+		if result.Stream != "" {
+			return result.Stream
+		}
+	}
+	return ""
+}
+
+// StreamResult is the variable of the streamed result
+func (results ActionResults) StreamResult() string {
+	for _, result := range results {
+		if result.Stream != "" {
+			return result.Variable
+		}
+	}
+	return ""
+}
+
 // Error expression if there is one
 func (results ActionResults) Error() string {
 	for _, result := range results {
@@ -215,6 +271,7 @@ type ActionResult struct {
 	Kind     parser.Kind
 	Variable string
 	IsError  bool
+	Stream   string // Response function that streams the result
 	Fields   []*ActionResultField
 	Methods  []*ActionResultMethod
 }
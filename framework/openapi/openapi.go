@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/livebud/bud/framework"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gotemplate"
+	"github.com/livebud/bud/package/imports"
+)
+
+//go:embed openapi.gotext
+var template string
+
+var generator = gotemplate.MustParse("framework/openapi/openapi.gotext", template)
+
+// Route to the OpenAPI document in development
+const Route = "/openapi.json"
+
+// Generate the OpenAPI handler from state
+func Generate(state *State) ([]byte, error) {
+	return generator.Generate(state)
+}
+
+// State of the OpenAPI handler
+type State struct {
+	Imports  []*imports.Import
+	Route    string
+	Document string
+}
+
+// New OpenAPI handler generator
+func New(flag *framework.Flag) *Generator {
+	return &Generator{flag}
+}
+
+// Generator serves the OpenAPI document in development
+type Generator struct {
+	flag *framework.Flag
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	// Only serve the document in development. Builds write bud/openapi.json.
+	if g.flag.Embed {
+		return fs.ErrNotExist
+	}
+	document, err := fs.ReadFile(fsys, "bud/openapi.json")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return fmt.Errorf("openapi: unable to read document. %w", err)
+	}
+	imports := imports.New()
+	imports.AddStd("net/http")
+	imports.AddNamed("router", "github.com/livebud/bud/package/router")
+	code, err := Generate(&State{
+		Imports:  imports.List(),
+		Route:    Route,
+		Document: string(document),
+	})
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
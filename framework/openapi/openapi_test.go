@@ -0,0 +1,99 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/package/testdir"
+)
+
+const usersController = `
+	package users
+	import "time"
+	type Controller struct {}
+	type User struct {
+		ID int ` + "`" + `json:"id"` + "`" + `
+		Email string ` + "`" + `json:"email" validate:"required,email"` + "`" + `
+		CreatedAt time.Time ` + "`" + `json:"created_at"` + "`" + `
+	}
+	func (c *Controller) Index(page int) ([]*User, error) {
+		return []*User{}, nil
+	}
+	func (c *Controller) Create(in *User) (*User, error) {
+		return in, nil
+	}
+	func (c *Controller) Show(id int) (*User, error) {
+		return &User{ID: id}, nil
+	}
+	func (c *Controller) Delete(id int) error {
+		return nil
+	}
+`
+
+func TestServeDocument(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = usersController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.GetJSON("/openapi.json")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	var doc map[string]interface{}
+	is.NoErr(json.Unmarshal(res.Body().Bytes(), &doc))
+	is.Equal(doc["openapi"], "3.1.0")
+	paths := doc["paths"].(map[string]interface{})
+	is.True(paths["/users"] != nil)
+	is.True(paths["/users/{id}"] != nil)
+	body := new(bytes.Buffer)
+	is.NoErr(json.Compact(body, res.Body().Bytes()))
+	is.In(body.String(), `"in":"path"`)
+	is.In(body.String(), `"application/problem+json"`)
+	is.In(body.String(), `"$ref":"#/components/schemas/User"`)
+	is.In(body.String(), `"email":{"type":"string","format":"email"}`)
+	is.In(body.String(), `"required":["email"]`)
+	is.In(body.String(), `"created_at":{"type":"string","format":"date-time"}`)
+	is.NoErr(app.Close())
+}
+
+func TestBuildDocument(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = usersController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	// The document is written, but not served in production
+	is.NoErr(td.Exists("bud/openapi.json"))
+	is.NoErr(td.NotExists("bud/internal/web/openapi"))
+	data, err := fs.ReadFile(td, "bud/openapi.json")
+	is.NoErr(err)
+	var doc map[string]interface{}
+	is.NoErr(json.Unmarshal(data, &doc))
+	is.Equal(doc["openapi"], "3.1.0")
+}
+
+func TestNoControllers(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.NoErr(td.NotExists("bud/openapi.json", "bud/internal/web/openapi"))
+}
@@ -0,0 +1,580 @@
+package spec
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/livebud/bud/framework/controller"
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/internal/gois"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/matthewmueller/gotext"
+)
+
+const uploadImport = "github.com/livebud/bud/package/upload"
+
+// Load the OpenAPI document from the controllers
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser) (*Document, error) {
+	state, err := controller.Load(fsys, injector, module, parser)
+	if err != nil {
+		return nil, err
+	}
+	loader := &loader{
+		module:  module,
+		parser:  parser,
+		schemas: map[string]*Schema{},
+		names:   map[string]string{},
+	}
+	return loader.Load(state)
+}
+
+type loader struct {
+	bail.Struct
+	module  *gomod.Module
+	parser  *parser.Parser
+	schemas map[string]*Schema // Component name to schema
+	names   map[string]string  // Full type name to component name
+}
+
+func (l *loader) Load(state *controller.State) (doc *Document, err error) {
+	defer l.Recover2(&err, "openapi: unable to load document")
+	doc = &Document{
+		OpenAPI: "3.1.0",
+		Info: &Info{
+			Title:   path.Base(l.module.Import()),
+			Version: "0.0.0",
+		},
+		Paths: map[string]*PathItem{},
+	}
+	l.loadController(doc, state.Controller)
+	if len(l.schemas) > 0 {
+		doc.Components = &Components{Schemas: l.schemas}
+	}
+	return doc, nil
+}
+
+func (l *loader) loadController(doc *Document, controller *controller.Controller) {
+	if len(controller.Actions) > 0 {
+		pkg, err := l.parser.Parse(path.Join("controller", controller.Path))
+		if err != nil {
+			l.Bail(err)
+		}
+		stct := pkg.Struct("Controller")
+		if stct == nil {
+			l.Bail(fmt.Errorf("unable to find the controller in %q", pkg.Directory()))
+		}
+		for _, action := range controller.Actions {
+			method := stct.Method(action.Name)
+			if method == nil {
+				l.Bail(fmt.Errorf("unable to find the %s action in %q", action.Name, pkg.Directory()))
+			}
+			l.loadOperation(doc, controller, action, method)
+		}
+	}
+	for _, subController := range controller.Controllers {
+		l.loadController(doc, subController)
+	}
+}
+
+func (l *loader) loadOperation(doc *Document, controller *controller.Controller, action *controller.Action, method *parser.Function) {
+	route, slots := l.loadPath(action.Route)
+	item, ok := doc.Paths[route]
+	if !ok {
+		item = new(PathItem)
+		doc.Paths[route] = item
+	}
+	operation := &Operation{
+		OperationID: gotext.Camel(controller.Name + " " + action.Name),
+		Responses:   map[string]*Response{},
+	}
+	if controller.Name != "" {
+		operation.Tags = []string{controller.Name}
+	}
+	switch action.Method {
+	case "Get":
+		item.Get = operation
+	case "Post":
+		item.Post = operation
+	case "Put":
+		item.Put = operation
+	case "Patch":
+		item.Patch = operation
+	case "Delete":
+		item.Delete = operation
+	default:
+		l.Bail(fmt.Errorf("unsupported method %q for %s", action.Method, action.Key))
+	}
+	// Handler functions control their own inputs and outputs
+	if action.HandlerFunc {
+		operation.Responses["default"] = &Response{Description: "Response written by the action"}
+		return
+	}
+	properties := l.loadInputs(action, method)
+	l.loadParameters(operation, action, slots, properties)
+	l.loadResponses(operation, action, method, len(properties) > 0)
+}
+
+// loadPath converts a route like /users/:id into /users/{id}
+func (l *loader) loadPath(route string) (string, []string) {
+	out := new(strings.Builder)
+	var slots []string
+	lexer := lex.New(route)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.ErrorToken:
+			l.Bail(fmt.Errorf("unable to parse route. %s", token.Value))
+		case lex.EndToken:
+			return out.String(), slots
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken:
+			slot := token.Slot()
+			slots = append(slots, slot)
+			out.WriteString("{" + slot + "}")
+		default:
+			out.WriteString(token.Value)
+		}
+	}
+}
+
+// property of an input or output object
+type property struct {
+	Name     string
+	Schema   *Schema
+	Required bool
+	Upload   bool
+	// In is set when the property is bound to a path slot, header or cookie
+	// through a struct tag. Param is the name in that location.
+	In    string
+	Param string
+}
+
+// loadInputs loads the action's inputs, flattening single struct inputs
+func (l *loader) loadInputs(action *controller.Action, method *parser.Function) (properties []*property) {
+	params := method.Params()
+	for i, ap := range action.Params {
+		if ap.IsContext() || ap.Socket {
+			continue
+		}
+		param := params[i]
+		// Single struct input
+		if ap.Variable == "in" {
+			def, err := param.Definition()
+			if err != nil {
+				l.Bail(err)
+			}
+			return l.loadProperties(l.loadStruct(def))
+		}
+		tag := reflect.StructTag(strings.Trim(ap.Tag, "`"))
+		properties = append(properties, l.loadProperty(ap.Snake, param.Type(), tag.Get("validate")))
+	}
+	return properties
+}
+
+func (l *loader) loadParameters(operation *Operation, action *controller.Action, slots []string, properties []*property) {
+	// Every slot in the route is a path parameter, even if the action doesn't
+	// take it as an input
+	isSlot := map[string]bool{}
+	for _, slot := range slots {
+		isSlot[slot] = true
+		schema := &Schema{Type: "string"}
+		for _, property := range properties {
+			if (property.In == "" && property.Name == slot) || (property.In == "path" && property.Param == slot) {
+				schema = property.Schema
+				break
+			}
+		}
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     slot,
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	body := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+	contentType := "application/json"
+	for _, property := range properties {
+		switch {
+		case property.In == "path":
+			continue
+		case property.In != "":
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:     property.Param,
+				In:       property.In,
+				Required: property.Required,
+				Schema:   property.Schema,
+			})
+		case isSlot[property.Name]:
+			continue
+		case action.Method == "Get" || action.Method == "Delete":
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:     property.Name,
+				In:       "query",
+				Required: property.Required,
+				Schema:   property.Schema,
+			})
+		default:
+			body.Properties[property.Name] = property.Schema
+			if property.Required {
+				body.Required = append(body.Required, property.Name)
+			}
+			if property.Upload {
+				contentType = "multipart/form-data"
+			}
+		}
+	}
+	if len(body.Properties) == 0 {
+		return
+	}
+	operation.RequestBody = &RequestBody{
+		Required: len(body.Required) > 0,
+		Content: map[string]*MediaType{
+			contentType: {Schema: body},
+		},
+	}
+}
+
+func (l *loader) loadResponses(operation *Operation, action *controller.Action, method *parser.Function, hasInputs bool) {
+	switch {
+	case action.Socket:
+		operation.Responses["101"] = &Response{Description: "Switching Protocols to a WebSocket"}
+	case action.Results.Stream() == "Events" || action.Results.Stream() == "Iterate":
+		operation.Responses["200"] = &Response{
+			Description: "Server-sent events with JSON data",
+			Content: map[string]*MediaType{
+				"text/event-stream": {Schema: &Schema{Type: "string"}},
+			},
+		}
+	case action.Results.Stream() == "Stream":
+		operation.Responses["200"] = &Response{
+			Description: "Streamed body",
+			Content: map[string]*MediaType{
+				"application/octet-stream": {Schema: &Schema{Type: "string", ContentMediaType: "application/octet-stream"}},
+			},
+		}
+	default:
+		if schema := l.loadResult(action, method); schema != nil {
+			operation.Responses["200"] = &Response{
+				Description: "OK",
+				Content: map[string]*MediaType{
+					"application/json": {Schema: schema},
+				},
+			}
+		} else {
+			operation.Responses["204"] = &Response{Description: "No Content"}
+		}
+	}
+	if hasInputs {
+		operation.Responses["400"] = &Response{
+			Description: "Bad Request",
+			Content: map[string]*MediaType{
+				"application/problem+json": {Schema: l.problemSchema()},
+			},
+		}
+		operation.Responses["422"] = &Response{
+			Description: "Unprocessable Entity",
+			Content: map[string]*MediaType{
+				"application/problem+json": {Schema: l.problemSchema()},
+			},
+		}
+	}
+	operation.Responses["default"] = &Response{
+		Description: "Error",
+		Content: map[string]*MediaType{
+			"application/problem+json": {Schema: l.problemSchema()},
+		},
+	}
+}
+
+// loadResult mirrors how the controller responds with the action's results
+func (l *loader) loadResult(action *controller.Action, method *parser.Function) *Schema {
+	results := method.Results()
+	var properties []*property
+	named := true
+	for i, result := range action.Results {
+		if result.IsError {
+			continue
+		}
+		if !result.Named {
+			named = false
+		}
+		properties = append(properties, &property{
+			Name:   result.Snake,
+			Schema: l.loadSchema(results[i].Type()),
+		})
+	}
+	switch {
+	case len(properties) == 0:
+		return nil
+	case len(properties) == 1:
+		return properties[0].Schema
+	case !named:
+		schema := &Schema{Type: "array"}
+		for _, property := range properties {
+			schema.PrefixItems = append(schema.PrefixItems, property.Schema)
+		}
+		return schema
+	default:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, property := range properties {
+			schema.Properties[property.Name] = property.Schema
+		}
+		return schema
+	}
+}
+
+func (l *loader) loadStruct(def parser.Declaration) *parser.Struct {
+	if stct, ok := def.(*parser.Struct); ok {
+		return stct
+	}
+	stct := def.Package().Struct(def.Name())
+	if stct == nil {
+		l.Bail(fmt.Errorf("unable to find struct %s", def.Name()))
+	}
+	return stct
+}
+
+// loadProperties loads the JSON properties of a struct
+func (l *loader) loadProperties(stct *parser.Struct) (properties []*property) {
+	for _, field := range stct.PublicFields() {
+		tags, err := field.Tags()
+		if err != nil {
+			l.Bail(err)
+		}
+		name := field.Name()
+		if value := tags.Get("json"); value == "-" {
+			continue
+		} else if value != "" {
+			name = value
+		}
+		property := l.loadProperty(name, field.Type(), validateTag(tags))
+		for _, in := range []string{"path", "header", "cookie"} {
+			if param := tags.Get(in); param != "" {
+				property.In = in
+				property.Param = param
+				break
+			}
+		}
+		properties = append(properties, property)
+	}
+	return properties
+}
+
+func (l *loader) loadProperty(name string, dt parser.Type, rules string) *property {
+	schema := l.loadSchema(dt)
+	required := applyRules(schema, rules)
+	upload, err := parser.IsImportType(dt, uploadImport, "File")
+	if err != nil {
+		l.Bail(err)
+	}
+	return &property{
+		Name:     name,
+		Schema:   schema,
+		Required: required,
+		Upload:   upload,
+	}
+}
+
+// loadSchema loads the JSON schema for a Go type
+func (l *loader) loadSchema(dt parser.Type) *Schema {
+	switch t := dt.(type) {
+	case *parser.StarType:
+		return l.loadSchema(t.Inner())
+	case *parser.ArrayType:
+		// []byte is encoded as a base64 string
+		if parser.TypeName(t.Inner()) == "byte" {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: l.loadSchema(t.Inner())}
+	case *parser.MapType:
+		return &Schema{Type: "object", AdditionalProperties: l.loadSchema(t.Value())}
+	case *parser.IdentType:
+		if parser.IsBuiltin(t) {
+			return builtinSchema(t.Name())
+		}
+		return l.loadNamedSchema(t)
+	case *parser.SelectorType:
+		return l.loadNamedSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func (l *loader) loadNamedSchema(dt parser.Type) *Schema {
+	importPath, err := parser.ImportPath(dt)
+	if err != nil {
+		l.Bail(err)
+	}
+	name := parser.TypeName(dt)
+	switch {
+	case importPath == "time" && name == "Time":
+		return &Schema{Type: "string", Format: "date-time"}
+	case importPath == "time" && name == "Duration":
+		return &Schema{Type: "integer"}
+	case importPath == uploadImport && name == "File":
+		return &Schema{Type: "string", ContentMediaType: "application/octet-stream"}
+	case gois.StdLib(importPath):
+		return &Schema{}
+	}
+	fullName := strconv.Quote(importPath) + "." + name
+	if component, ok := l.names[fullName]; ok {
+		return ref(component)
+	}
+	def, err := parser.Definition(dt)
+	if err != nil {
+		l.Bail(err)
+	}
+	switch decl := def.(type) {
+	case *parser.Alias:
+		return l.loadSchema(decl.Type())
+	case *parser.TypeSpec:
+		return l.loadSchema(decl.Type())
+	}
+	if def.Kind() != parser.KindStruct {
+		return &Schema{}
+	}
+	component := l.componentName(def.Package(), name, fullName)
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	// Add the component before loading the properties to support recursion
+	l.schemas[component] = schema
+	for _, property := range l.loadProperties(l.loadStruct(def)) {
+		schema.Properties[property.Name] = property.Schema
+		if property.Required {
+			schema.Required = append(schema.Required, property.Name)
+		}
+	}
+	return ref(component)
+}
+
+// componentName picks a unique name for the component, prefixing the package
+// name when two packages have a type with the same name
+func (l *loader) componentName(pkg *parser.Package, name, fullName string) string {
+	component := name
+	if _, ok := l.schemas[component]; ok {
+		component = gotext.Pascal(pkg.Name()) + name
+	}
+	for i := 2; ; i++ {
+		if _, ok := l.schemas[component]; !ok {
+			break
+		}
+		component = gotext.Pascal(pkg.Name()) + name + strconv.Itoa(i)
+	}
+	l.names[fullName] = component
+	return component
+}
+
+func (l *loader) problemSchema() *Schema {
+	if _, ok := l.schemas["Problem"]; !ok {
+		l.schemas["Problem"] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"type":   {Type: "string"},
+				"title":  {Type: "string"},
+				"status": {Type: "integer"},
+				"detail": {Type: "string"},
+				"fields": {
+					Type: "object",
+					AdditionalProperties: &Schema{
+						Type:  "array",
+						Items: &Schema{Type: "string"},
+					},
+				},
+			},
+			Required: []string{"type", "title", "status"},
+		}
+	}
+	return ref("Problem")
+}
+
+// validateTag joins the validate tag back together since the parser splits
+// the options on commas
+func validateTag(tags parser.Tags) string {
+	for _, tag := range tags {
+		if tag.Key == "validate" {
+			return strings.Join(append([]string{tag.Value}, tag.Options...), ",")
+		}
+	}
+	return ""
+}
+
+func ref(component string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + component}
+}
+
+func builtinSchema(name string) *Schema {
+	switch name {
+	case "string", "error":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"byte", "rune":
+		return &Schema{Type: "integer"}
+	case "float32", "float64":
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{}
+	}
+}
+
+// applyRules adds the constraints from the validate tag to the schema and
+// returns true if the value is required
+func applyRules(schema *Schema, rules string) (required bool) {
+	for _, rule := range strings.Split(rules, ",") {
+		key, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "oneof":
+			schema.Enum = strings.Fields(arg)
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			applySize(schema, key, n)
+		}
+	}
+	return required
+}
+
+func applySize(schema *Schema, rule string, n float64) {
+	size := int(n)
+	switch schema.Type {
+	case "string":
+		if rule == "min" || rule == "len" {
+			schema.MinLength = &size
+		}
+		if rule == "max" || rule == "len" {
+			schema.MaxLength = &size
+		}
+	case "array":
+		if rule == "min" || rule == "len" {
+			schema.MinItems = &size
+		}
+		if rule == "max" || rule == "len" {
+			schema.MaxItems = &size
+		}
+	case "integer", "number":
+		if rule == "min" || rule == "len" {
+			schema.Minimum = &n
+		}
+		if rule == "max" || rule == "len" {
+			schema.Maximum = &n
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+)
+
+// New OpenAPI document generator
+func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, module, parser}
+}
+
+// Generator for the OpenAPI document
+type Generator struct {
+	injector *di.Injector
+	module   *gomod.Module
+	parser   *parser.Parser
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	doc, err := Load(fsys, g.injector, g.module, g.parser)
+	if err != nil {
+		return fmt.Errorf("openapi: unable to load. %w", err)
+	}
+	code, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	file.Data = append(code, '\n')
+	return nil
+}
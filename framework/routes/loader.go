@@ -0,0 +1,245 @@
+package routes
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/livebud/bud/framework/controller"
+	"github.com/livebud/bud/internal/bail"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/matthewmueller/gotext"
+)
+
+// Load the route helpers from the controllers
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser) (*State, error) {
+	state, err := controller.Load(fsys, injector, module, parser)
+	if err != nil {
+		return nil, err
+	}
+	loader := &loader{parser: parser}
+	return loader.Load(state)
+}
+
+type loader struct {
+	bail.Struct
+	parser *parser.Parser
+}
+
+func (l *loader) Load(controller *controller.State) (state *State, err error) {
+	defer l.Recover2(&err, "routes: unable to load state")
+	state = new(State)
+	state.Routes = l.loadController(controller.Controller)
+	return state, nil
+}
+
+func (l *loader) loadController(controller *controller.Controller) (routes []*Route) {
+	if len(controller.Actions) > 0 {
+		pkg, err := l.parser.Parse(path.Join("controller", controller.Path))
+		if err != nil {
+			l.Bail(err)
+		}
+		stct := pkg.Struct("Controller")
+		if stct == nil {
+			l.Bail(fmt.Errorf("unable to find the controller in %q", pkg.Directory()))
+		}
+		for _, action := range controller.Actions {
+			method := stct.Method(action.Name)
+			if method == nil {
+				l.Bail(fmt.Errorf("unable to find the %s action in %q", action.Name, pkg.Directory()))
+			}
+			routes = append(routes, l.loadRoute(controller, action, method))
+		}
+	}
+	for _, subController := range controller.Controllers {
+		routes = append(routes, l.loadController(subController)...)
+	}
+	return routes
+}
+
+func (l *loader) loadRoute(controller *controller.Controller, action *controller.Action, method *parser.Function) *Route {
+	route := &Route{
+		Name:   gotext.Pascal(controller.Name + " " + action.Name),
+		Camel:  gotext.Camel(controller.Name + " " + action.Name),
+		Method: strings.ToUpper(action.Method),
+		Route:  action.Route,
+	}
+	types := l.loadSlotTypes(action, method)
+	literal := new(strings.Builder)
+	lexer := lex.New(action.Route)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.ErrorToken:
+			l.Bail(fmt.Errorf("unable to parse route. %s", token.Value))
+		case lex.EndToken:
+			if literal.Len() > 0 {
+				route.Parts = append(route.Parts, &Part{Literal: literal.String()})
+			}
+			return route
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken:
+			slot := token.Slot()
+			param := &Param{
+				Name:     paramName(slot),
+				Slot:     slot,
+				Type:     "string",
+				Optional: token.Type != lex.SlotToken,
+			}
+			// Wildcards are always strings, they may contain slashes
+			if t, ok := types[strings.ToLower(slot)]; ok && token.Type != lex.StarToken {
+				param.Type = t
+			}
+			// Optional slots that aren't strings are pointers, so they can be left out
+			if token.Type == lex.QuestionToken && param.Type != "string" {
+				param.Type = "*" + param.Type
+			}
+			route.Params = append(route.Params, param)
+			// Optional slots include the slash before them
+			prefix := literal.String()
+			if param.Optional {
+				prefix = strings.TrimSuffix(prefix, "/")
+			}
+			literal.Reset()
+			if prefix != "" {
+				route.Parts = append(route.Parts, &Part{Literal: prefix})
+			}
+			route.Parts = append(route.Parts, &Part{
+				Param:    param,
+				Wildcard: token.Type == lex.StarToken,
+			})
+		default:
+			literal.WriteString(token.Value)
+		}
+	}
+}
+
+// loadSlotTypes loads the types of the action's inputs by their lowercased
+// name. Only basic types are kept, slots of other types are strings.
+func (l *loader) loadSlotTypes(action *controller.Action, method *parser.Function) map[string]string {
+	types := map[string]string{}
+	methodParams := method.Params()
+	for i, ap := range action.Params {
+		if ap.IsContext() || ap.Upload || ap.Socket {
+			continue
+		}
+		// Single struct input
+		if ap.Variable == "in" {
+			l.loadFieldTypes(types, methodParams[i])
+			continue
+		}
+		if t, ok := basicType(methodParams[i].Type()); ok {
+			types[strings.ToLower(ap.Snake)] = t
+		}
+	}
+	return types
+}
+
+// loadFieldTypes loads the types of a struct input's fields by the slot that
+// fills them in
+func (l *loader) loadFieldTypes(types map[string]string, param *parser.Param) {
+	def, err := param.Definition()
+	if err != nil {
+		l.Bail(err)
+	}
+	stct := def.Package().Struct(def.Name())
+	if stct == nil {
+		l.Bail(fmt.Errorf("unable to find struct for %s", param.Type()))
+	}
+	for _, field := range stct.PublicFields() {
+		tags, err := field.Tags()
+		if err != nil {
+			l.Bail(err)
+		}
+		key := field.Name()
+		if value := tags.Get("path"); value != "" {
+			key = value
+		} else if value := tags.Get("json"); value == "-" {
+			continue
+		} else if name := strings.Split(value, ",")[0]; name != "" {
+			// Options like omitempty come after the name, as in encoding/json
+			key = name
+		}
+		if t, ok := basicType(field.Type()); ok {
+			types[strings.ToLower(key)] = t
+		}
+	}
+}
+
+// basicType returns the type if it's a string, boolean or number, looking
+// through pointers
+func basicType(dt parser.Type) (string, bool) {
+	if star, ok := dt.(*parser.StarType); ok {
+		return basicType(star.Inner())
+	}
+	ident, ok := dt.(*parser.IdentType)
+	if !ok || !parser.IsBuiltin(ident) {
+		return "", false
+	}
+	switch name := ident.Name(); name {
+	case "string", "bool",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return name, true
+	default:
+		return "", false
+	}
+}
+
+// paramName returns the name of the parameter for a slot. gotext escapes Go
+// keywords and builtins like _type, the JS reserved words are escaped the same
+// way, so both routes.go and routes.ts compile.
+func paramName(slot string) string {
+	name := gotext.Camel(slot)
+	if reserved[name] {
+		return name + "Slot"
+	}
+	if jsReserved[name] {
+		return "_" + name
+	}
+	return name
+}
+
+// reserved names are used by the generated helpers
+var reserved = map[string]bool{
+	"pathSlot":     true,
+	"optionalSlot": true,
+	"wildcardSlot": true,
+}
+
+// jsReserved words that gotext doesn't already escape
+var jsReserved = map[string]bool{
+	"arguments":  true,
+	"await":      true,
+	"catch":      true,
+	"class":      true,
+	"debugger":   true,
+	"enum":       true,
+	"eval":       true,
+	"export":     true,
+	"extends":    true,
+	"finally":    true,
+	"function":   true,
+	"implements": true,
+	"in":         true,
+	"instanceof": true,
+	"let":        true,
+	"null":       true,
+	"private":    true,
+	"protected":  true,
+	"public":     true,
+	"static":     true,
+	"super":      true,
+	"this":       true,
+	"throw":      true,
+	"try":        true,
+	"typeof":     true,
+	"void":       true,
+	"while":      true,
+	"with":       true,
+	"yield":      true,
+}
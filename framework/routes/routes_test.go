@@ -0,0 +1,123 @@
+package routes_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/package/testdir"
+)
+
+func TestGenerateRoutes(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/posts/comments/controller.go"] = `
+		package comments
+		type Controller struct {}
+		type Comment struct {
+			ID int ` + "`" + `json:"id"` + "`" + `
+			PostID int ` + "`" + `json:"post_id"` + "`" + `
+		}
+		func (c *Controller) Index(postID int) []*Comment {
+			return nil
+		}
+		func (c *Controller) Show(postID, id int) *Comment {
+			return &Comment{id, postID}
+		}
+		func (c *Controller) Update(in *Comment) error {
+			return nil
+		}
+	`
+	td.Files["controller/docs/controller.go"] = `
+		package docs
+		type Controller struct {}
+		//bud:route GET /docs/:path*
+		func (c *Controller) Show(path string) string {
+			return path
+		}
+		//bud:route GET /versions/:version/:page?
+		func (c *Controller) Version(version string, page *int) string {
+			return version
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	data, err := fs.ReadFile(td, "bud/routes/routes.go")
+	is.NoErr(err)
+	code := string(data)
+	is.In(code, `func PostsCommentsIndex(postID int) string {`)
+	is.In(code, `return "/posts/" + pathSlot(postID) + "/comments"`)
+	is.In(code, `func PostsCommentsShow(postID int, id int) string {`)
+	is.In(code, `return "/posts/" + pathSlot(postID) + "/comments/" + pathSlot(id)`)
+	// Slot types come from struct inputs too
+	is.In(code, `func PostsCommentsUpdate(postID int, id int) string {`)
+	is.In(code, `func DocsShow(path string) string {`)
+	is.In(code, `return "/docs" + wildcardSlot(path)`)
+	// Optional slots that aren't strings are pointers
+	is.In(code, `func DocsVersion(version string, page *int) string {`)
+	is.In(code, `return "/versions/" + pathSlot(version) + optionalSlot(page)`)
+	// The generated routes compile
+	cmd := exec.CommandContext(ctx, "go", "vet", "-mod=mod", "./bud/routes")
+	cmd.Dir = td.Directory()
+	cmd.Stderr = os.Stderr
+	is.NoErr(cmd.Run())
+}
+
+func TestNoControllers(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.NoErr(td.NotExists("bud/routes"))
+}
+
+func TestKeywordSlots(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/filters/controller.go"] = `
+		package filters
+		type Controller struct {}
+		type Filter struct {
+			Type string ` + "`" + `json:"type,omitempty"` + "`" + `
+			ID int ` + "`" + `json:"id,omitempty"` + "`" + `
+			New bool ` + "`" + `json:"new"` + "`" + `
+			Class string ` + "`" + `json:"class"` + "`" + `
+		}
+		//bud:route GET /filters/:type/:id/:new/:class
+		func (c *Controller) Show(in *Filter) *Filter {
+			return in
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	data, err := fs.ReadFile(td, "bud/routes/routes.go")
+	is.NoErr(err)
+	code := string(data)
+	// Keywords are renamed and json tag options are ignored
+	is.In(code, `func FiltersShow(_type string, id int, _new bool, _class string) string {`)
+	is.In(code, `return "/filters/" + pathSlot(_type) + "/" + pathSlot(id) + "/" + pathSlot(_new) + "/" + pathSlot(_class)`)
+	data, err = fs.ReadFile(td, "bud/routes.ts")
+	is.NoErr(err)
+	is.In(string(data), `export function filtersShow(_type: string, id: number, _new: boolean, _class: string): string {`)
+	// The generated routes compile
+	cmd := exec.CommandContext(ctx, "go", "vet", "-mod=mod", "./bud/routes")
+	cmd.Dir = td.Directory()
+	cmd.Stderr = os.Stderr
+	is.NoErr(cmd.Run())
+}
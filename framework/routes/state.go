@@ -0,0 +1,45 @@
+package routes
+
+import "strings"
+
+// State of the route helpers
+type State struct {
+	Routes []*Route
+}
+
+// Route is a helper that builds the path to a controller action
+type Route struct {
+	Name   string // Name of the helper, e.g. PostsCommentsShow
+	Camel  string // Camel-cased name for JS, e.g. postsCommentsShow
+	Method string // HTTP method
+	Route  string // Route with :slots
+	Params []*Param
+	Parts  []*Part // Parts of the path, in order
+}
+
+// Param of the route helper, one for each slot
+type Param struct {
+	Name     string // Name of the parameter
+	Slot     string // Slot in the route
+	Type     string // Go type of the parameter
+	Optional bool   // Optional is true for :slot? and :slot* slots
+}
+
+// JS type of the parameter
+func (p *Param) JS() string {
+	switch strings.TrimPrefix(p.Type, "*") {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	default:
+		return "number"
+	}
+}
+
+// Part of a path. Either a literal path or a parameter that's filled in.
+type Part struct {
+	Literal  string
+	Param    *Param
+	Wildcard bool // Wildcard is true for :slot* slots
+}
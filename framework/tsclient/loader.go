@@ -0,0 +1,222 @@
+package tsclient
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/livebud/bud/framework/openapi/spec"
+)
+
+// State of the TypeScript client
+type State struct {
+	Types   []*Type
+	Actions []*Action
+}
+
+// Type is an exported type alias
+type Type struct {
+	Name       string
+	Definition string
+}
+
+// Action is a function that calls a controller action
+type Action struct {
+	Name      string // Name of the function
+	Method    string // HTTP method
+	Route     string // Route with :slots
+	Path      string // Path as a template literal
+	Input     string // Input type, if any
+	Output    string // Output type
+	Query     string // Query string object literal, if any
+	Body      string // Body object literal, if any
+	Headers   string // Headers object literal, if any
+	Multipart bool   // Multipart is true if the body contains files
+}
+
+// Load the client state from the OpenAPI document
+func Load(doc *spec.Document) (*State, error) {
+	state := new(State)
+	if doc.Components != nil {
+		names := make([]string, 0, len(doc.Components.Schemas))
+		for name := range doc.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			state.Types = append(state.Types, &Type{
+				Name:       name,
+				Definition: typeOf(doc.Components.Schemas[name], true),
+			})
+		}
+	}
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		operations := []struct {
+			method    string
+			operation *spec.Operation
+		}{
+			{"GET", item.Get},
+			{"POST", item.Post},
+			{"PUT", item.Put},
+			{"PATCH", item.Patch},
+			{"DELETE", item.Delete},
+		}
+		for _, op := range operations {
+			// Streamed responses are read with EventSource or fetch instead and
+			// WebSockets are connected to with livebud/runtime/socket
+			if op.operation == nil || isStream(op.operation) || isSocket(op.operation) {
+				continue
+			}
+			state.Actions = append(state.Actions, loadAction(path, op.method, op.operation))
+		}
+	}
+	return state, nil
+}
+
+// isStream returns true if the operation responds with a stream instead of JSON
+func isStream(operation *spec.Operation) bool {
+	response, ok := operation.Responses["200"]
+	if !ok {
+		return false
+	}
+	_, ok = response.Content["application/json"]
+	return !ok
+}
+
+// isSocket returns true if the operation upgrades to a WebSocket
+func isSocket(operation *spec.Operation) bool {
+	_, ok := operation.Responses["101"]
+	return ok
+}
+
+var slotPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func loadAction(path, method string, operation *spec.Operation) *Action {
+	action := &Action{
+		Name:   operation.OperationID,
+		Method: method,
+		Route:  slotPattern.ReplaceAllString(path, ":$1"),
+		Path:   "`" + slotPattern.ReplaceAllString(path, "${slot(input[\"$1\"])}") + "`",
+		Output: "void",
+	}
+	var fields, query, body, headers []string
+	for _, param := range operation.Parameters {
+		// Cookies are sent by the browser
+		if param.In == "cookie" {
+			continue
+		}
+		fields = append(fields, field(param.Name, typeOf(param.Schema, false), !param.Required))
+		switch param.In {
+		case "query":
+			query = append(query, strconv.Quote(param.Name)+": input["+strconv.Quote(param.Name)+"]")
+		case "header":
+			headers = append(headers, strconv.Quote(param.Name)+": input["+strconv.Quote(param.Name)+"]")
+		}
+	}
+	if operation.RequestBody != nil {
+		for contentType, media := range operation.RequestBody.Content {
+			action.Multipart = contentType == "multipart/form-data"
+			required := map[string]bool{}
+			for _, name := range media.Schema.Required {
+				required[name] = true
+			}
+			for _, name := range sortedKeys(media.Schema.Properties) {
+				fields = append(fields, field(name, typeOf(media.Schema.Properties[name], false), !required[name]))
+				body = append(body, strconv.Quote(name)+": input["+strconv.Quote(name)+"]")
+			}
+		}
+	}
+	if len(fields) > 0 {
+		action.Input = "{ " + strings.Join(fields, "; ") + " }"
+	}
+	if len(query) > 0 {
+		action.Query = "{ " + strings.Join(query, ", ") + " }"
+	}
+	if len(body) > 0 {
+		action.Body = "{ " + strings.Join(body, ", ") + " }"
+	}
+	if len(headers) > 0 {
+		action.Headers = "{ " + strings.Join(headers, ", ") + " }"
+	}
+	if response, ok := operation.Responses["200"]; ok {
+		if media, ok := response.Content["application/json"]; ok {
+			action.Output = typeOf(media.Schema, true)
+		}
+	}
+	return action
+}
+
+// typeOf converts the JSON schema into a TypeScript type. Properties of
+// results are always present, so they're only optional for inputs.
+func typeOf(schema *spec.Schema, output bool) string {
+	switch {
+	case schema.Ref != "":
+		return strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+	case len(schema.Enum) > 0 && schema.Type == "string":
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = strconv.Quote(value)
+		}
+		return strings.Join(values, " | ")
+	case schema.Type == "string" && schema.ContentMediaType != "":
+		return "Blob"
+	case schema.Type == "string":
+		return "string"
+	case schema.Type == "integer" || schema.Type == "number":
+		return "number"
+	case schema.Type == "boolean":
+		return "boolean"
+	case schema.Type == "array" && len(schema.PrefixItems) > 0:
+		items := make([]string, len(schema.PrefixItems))
+		for i, item := range schema.PrefixItems {
+			items[i] = typeOf(item, output)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case schema.Type == "array" && schema.Items != nil:
+		return "Array<" + typeOf(schema.Items, output) + ">"
+	case schema.Type == "object" && len(schema.Properties) > 0:
+		required := map[string]bool{}
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+		fields := make([]string, 0, len(schema.Properties))
+		for _, name := range sortedKeys(schema.Properties) {
+			fields = append(fields, field(name, typeOf(schema.Properties[name], output), !output && !required[name]))
+		}
+		return "{ " + strings.Join(fields, "; ") + " }"
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		return "Record<string, " + typeOf(schema.AdditionalProperties, output) + ">"
+	case schema.Type == "object":
+		return "Record<string, any>"
+	default:
+		return "any"
+	}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func field(name, dataType string, optional bool) string {
+	if !identifier.MatchString(name) {
+		name = strconv.Quote(name)
+	}
+	if optional {
+		name += "?"
+	}
+	return name + ": " + dataType
+}
+
+func sortedKeys(properties map[string]*spec.Schema) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package tsclient
+
+import (
+	_ "embed"
+	"fmt"
+	"io/fs"
+
+	esbuild "github.com/evanw/esbuild/pkg/api"
+	"github.com/livebud/bud/framework/openapi/spec"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/gotemplate"
+	"github.com/livebud/bud/package/parser"
+)
+
+//go:embed tsclient.gotext
+var template string
+
+var generator = gotemplate.MustParse("framework/tsclient/tsclient.gotext", template)
+
+// Path to the generated client
+const Path = "bud/client.ts"
+
+// Generate the TypeScript client from state
+func Generate(state *State) ([]byte, error) {
+	return generator.Generate(state)
+}
+
+// New TypeScript client generator
+func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, module, parser}
+}
+
+// Generator for the TypeScript client
+type Generator struct {
+	injector *di.Injector
+	module   *gomod.Module
+	parser   *parser.Parser
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	doc, err := spec.Load(fsys, g.injector, g.module, g.parser)
+	if err != nil {
+		return fmt.Errorf("tsclient: unable to load. %w", err)
+	}
+	state, err := Load(doc)
+	if err != nil {
+		return err
+	}
+	code, err := Generate(state)
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
+
+// Plugin resolves `import { ... } from "bud/client"` to the generated client
+func Plugin(fsys fs.FS, dir string) esbuild.Plugin {
+	return esbuild.Plugin{
+		Name: "tsclient",
+		Setup: func(epb esbuild.PluginBuild) {
+			epb.OnResolve(esbuild.OnResolveOptions{Filter: `^bud\/client(\.ts)?$`}, func(args esbuild.OnResolveArgs) (result esbuild.OnResolveResult, err error) {
+				result.Namespace = "tsclient"
+				result.Path = Path
+				return result, nil
+			})
+			epb.OnLoad(esbuild.OnLoadOptions{Filter: `.*`, Namespace: "tsclient"}, func(args esbuild.OnLoadArgs) (result esbuild.OnLoadResult, err error) {
+				code, err := fs.ReadFile(fsys, Path)
+				if err != nil {
+					return result, fmt.Errorf("tsclient: unable to read %q. %w", Path, err)
+				}
+				contents := string(code)
+				result.ResolveDir = dir
+				result.Contents = &contents
+				result.Loader = esbuild.LoaderTS
+				return result, nil
+			})
+		},
+	}
+}
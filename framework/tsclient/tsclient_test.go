@@ -0,0 +1,101 @@
+package tsclient_test
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/internal/versions"
+	"github.com/livebud/bud/package/testdir"
+)
+
+const usersController = `
+	package users
+	type Controller struct {}
+	type User struct {
+		ID int ` + "`" + `json:"id"` + "`" + `
+		Email string ` + "`" + `json:"email" validate:"required,email"` + "`" + `
+	}
+	func (c *Controller) Index(page int) ([]*User, error) {
+		return []*User{}, nil
+	}
+	func (c *Controller) Create(in *User) (*User, error) {
+		return in, nil
+	}
+	func (c *Controller) Show(id int) (*User, error) {
+		return &User{ID: id}, nil
+	}
+	func (c *Controller) Delete(id int) error {
+		return nil
+	}
+`
+
+func TestGenerateClient(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = usersController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	data, err := fs.ReadFile(td, "bud/client.ts")
+	is.NoErr(err)
+	code := string(data)
+	is.In(code, `export type User = { email: string; id: number }`)
+	is.In(code, `Accept: "application/json"`)
+	is.In(code, "export function usersIndex(input: { page?: number }): Promise<Array<User>> {")
+	is.In(code, "return request(\"GET\", `/users` + query({ \"page\": input[\"page\"] }))")
+	is.In(code, "export function usersCreate(input: { email: string; id?: number }): Promise<User> {")
+	is.In(code, "return request(\"POST\", `/users`, { \"email\": input[\"email\"], \"id\": input[\"id\"] })")
+	is.In(code, "export function usersShow(input: { id: number }): Promise<User> {")
+	is.In(code, "return request(\"GET\", `/users/${slot(input[\"id\"])}`)")
+	is.In(code, "export function usersDelete(input: { id: number }): Promise<void> {")
+}
+
+func TestImportClient(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/users/controller.go"] = usersController
+	td.Files["view/users/show.svelte"] = `
+		<script>
+			import { usersShow } from "bud/client"
+			export let user = {}
+			const load = () => usersShow({ id: user.id })
+		</script>
+		<h1 on:click={load}>{user.id}</h1>
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/users/10")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), "<h1>10</h1>")
+	res, err = app.Get("/bud/view/users/_show.svelte.js")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), `application/json`)
+	is.NoErr(app.Close())
+}
+
+func TestNoControllers(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	is.NoErr(td.NotExists("bud/client.ts"))
+}
@@ -0,0 +1,78 @@
+package tsroutes
+
+import (
+	_ "embed"
+	"fmt"
+	"io/fs"
+
+	esbuild "github.com/evanw/esbuild/pkg/api"
+	"github.com/livebud/bud/framework/routes"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/gotemplate"
+	"github.com/livebud/bud/package/parser"
+)
+
+//go:embed tsroutes.gotext
+var template string
+
+var generator = gotemplate.MustParse("framework/tsroutes/tsroutes.gotext", template)
+
+// Path to the generated route helpers
+const Path = "bud/routes.ts"
+
+// Generate the TypeScript route helpers from state
+func Generate(state *routes.State) ([]byte, error) {
+	return generator.Generate(state)
+}
+
+// New TypeScript route helpers generator
+func New(injector *di.Injector, module *gomod.Module, parser *parser.Parser) *Generator {
+	return &Generator{injector, module, parser}
+}
+
+// Generator for the TypeScript route helpers
+type Generator struct {
+	injector *di.Injector
+	module   *gomod.Module
+	parser   *parser.Parser
+}
+
+func (g *Generator) GenerateFile(fsys genfs.FS, file *genfs.File) error {
+	state, err := routes.Load(fsys, g.injector, g.module, g.parser)
+	if err != nil {
+		return fmt.Errorf("tsroutes: unable to load. %w", err)
+	}
+	code, err := Generate(state)
+	if err != nil {
+		return err
+	}
+	file.Data = code
+	return nil
+}
+
+// Plugin resolves `import { ... } from "bud/routes"` to the generated helpers
+func Plugin(fsys fs.FS, dir string) esbuild.Plugin {
+	return esbuild.Plugin{
+		Name: "tsroutes",
+		Setup: func(epb esbuild.PluginBuild) {
+			epb.OnResolve(esbuild.OnResolveOptions{Filter: `^bud\/routes(\.ts)?$`}, func(args esbuild.OnResolveArgs) (result esbuild.OnResolveResult, err error) {
+				result.Namespace = "tsroutes"
+				result.Path = Path
+				return result, nil
+			})
+			epb.OnLoad(esbuild.OnLoadOptions{Filter: `.*`, Namespace: "tsroutes"}, func(args esbuild.OnLoadArgs) (result esbuild.OnLoadResult, err error) {
+				code, err := fs.ReadFile(fsys, Path)
+				if err != nil {
+					return result, fmt.Errorf("tsroutes: unable to read %q. %w", Path, err)
+				}
+				contents := string(code)
+				result.ResolveDir = dir
+				result.Contents = &contents
+				result.Loader = esbuild.LoaderTS
+				return result, nil
+			})
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package tsroutes_test
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/internal/versions"
+	"github.com/livebud/bud/package/testdir"
+)
+
+const commentsController = `
+	package comments
+	type Controller struct {}
+	type Comment struct {
+		ID int ` + "`" + `json:"id"` + "`" + `
+		PostID int ` + "`" + `json:"post_id"` + "`" + `
+	}
+	func (c *Controller) Index(postID int) []*Comment {
+		return []*Comment{{1, postID}}
+	}
+	func (c *Controller) Show(postID, id int) *Comment {
+		return &Comment{id, postID}
+	}
+`
+
+func TestGenerateRoutes(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/posts/comments/controller.go"] = commentsController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.NoErr(err)
+	data, err := fs.ReadFile(td, "bud/routes.ts")
+	is.NoErr(err)
+	code := string(data)
+	is.In(code, "export function postsCommentsIndex(postID: number): string {")
+	is.In(code, `return "/posts/" + pathSlot(postID) + "/comments"`)
+	is.In(code, "export function postsCommentsShow(postID: number, id: number): string {")
+	is.In(code, `return "/posts/" + pathSlot(postID) + "/comments/" + pathSlot(id)`)
+}
+
+func TestImportRoutes(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/posts/comments/controller.go"] = commentsController
+	td.Files["view/posts/comments/index.svelte"] = `
+		<script>
+			import { postsCommentsShow } from "bud/routes"
+			export let comments = []
+		</script>
+		{#each comments as comment}
+			<a href={postsCommentsShow(comment.post_id, comment.id)}>{comment.id}</a>
+		{/each}
+	`
+	td.NodeModules["svelte"] = versions.Svelte
+	td.NodeModules["livebud"] = "*"
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/posts/10/comments")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.In(res.Body().String(), `<a href="/posts/10/comments/1">1</a>`)
+	is.NoErr(app.Close())
+}
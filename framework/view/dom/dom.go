@@ -13,6 +13,8 @@ import (
 
 	esbuild "github.com/evanw/esbuild/pkg/api"
 	"github.com/livebud/bud/framework/transform/transformrt"
+	"github.com/livebud/bud/framework/tsclient"
+	"github.com/livebud/bud/framework/tsroutes"
 	"github.com/livebud/bud/internal/entrypoint"
 	"github.com/livebud/bud/internal/esmeta"
 	"github.com/livebud/bud/package/gomod"
@@ -69,6 +71,8 @@ func (c *Generator) Compile(fsys fs.FS) ([]esbuild.OutputFile, error) {
 		MinifySyntax:      true,
 		MinifyWhitespace:  true,
 		Plugins: append([]esbuild.Plugin{
+			tsclient.Plugin(fsys, c.module.Directory()),
+			tsroutes.Plugin(fsys, c.module.Directory()),
 			domPlugin(fsys, c.module),
 		}, c.transformer.DOM.Plugins()...),
 		Write: false,
@@ -130,6 +134,8 @@ func (c *Generator) ServeFile(fsys genfs.FS, file *genfs.File) error {
 		Metafile:   true,
 		Bundle:     true,
 		Plugins: append([]esbuild.Plugin{
+			tsclient.Plugin(fsys, c.module.Directory()),
+			tsroutes.Plugin(fsys, c.module.Directory()),
 			domPlugin(fsys, c.module),
 			domExternalizePlugin(),
 		}, c.transformer.DOM.Plugins()...),
@@ -14,6 +14,8 @@ import (
 
 	esbuild "github.com/evanw/esbuild/pkg/api"
 	"github.com/livebud/bud/framework/transform/transformrt"
+	"github.com/livebud/bud/framework/tsclient"
+	"github.com/livebud/bud/framework/tsroutes"
 	"github.com/livebud/bud/internal/entrypoint"
 	"github.com/livebud/bud/internal/esmeta"
 	"github.com/livebud/bud/package/genfs"
@@ -71,6 +73,8 @@ func (c *Generator) Compile(fsys fileSystem) ([]byte, error) {
 		Bundle:        true,
 		Metafile:      true,
 		Plugins: append([]esbuild.Plugin{
+			tsclient.Plugin(fsys, dir),
+			tsroutes.Plugin(fsys, dir),
 			ssrPlugin(fsys, dir),
 			ssrRuntimePlugin(fsys, dir),
 			jsxPlugin(fsys, dir),
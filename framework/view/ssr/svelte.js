@@ -287,6 +287,9 @@ var import_jsesc = __toESM(require_jsesc());
 function createView(view) {
   view.layout = view.layout || defaultLayout;
   return function({ props, context }) {
+    if (context && context.error) {
+      return renderError(view, context.error);
+    }
     const page = view.page.render(props);
     let css = page.css.code;
     let html = page.html;
@@ -315,6 +318,42 @@ function createView(view) {
     };
   };
 }
+function renderError(view, error) {
+  const props = { status: error.status, message: error.message };
+  const page = (view.error || defaultError).render(props);
+  const layout = view.layout.render(props, {
+    head: function() {
+      return `
+        ${page.head}
+        <style>#bud{}${page.css.code}</style>
+      `;
+    },
+    default: function() {
+      return '<div id="bud_target">' + page.html + "</div>";
+    }
+  });
+  return {
+    status: error.status,
+    headers: {
+      "Content-Type": "text/html"
+    },
+    body: layout.html.replace("#bud{}", layout.css.code)
+  };
+}
+var defaultError = {
+  render(props) {
+    return {
+      css: {
+        code: ""
+      },
+      head: "",
+      html: `<h1>${props.status}</h1><p>${escape(props.message)}</p>`
+    };
+  }
+};
+function escape(text) {
+  return String(text).replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;").replace(/"/g, "&quot;");
+}
 var defaultLayout = {
   render(props, slots) {
     return {
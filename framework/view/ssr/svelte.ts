@@ -11,11 +11,13 @@ type View = {
 // TODO:
 // - Test custom layouts
 // - Support frames
-// - Support default errors
-// - Support custom errors
 export function createView(view: View) {
   view.layout = view.layout || defaultLayout
   return function ({ props, context }) {
+    // Render the nearest error page instead when there's an error
+    if (context && context.error) {
+      return renderError(view, context.error)
+    }
     const page = view.page.render(props)
     let css = page.css.code
     let html = page.html
@@ -46,6 +48,56 @@ export function createView(view: View) {
   }
 }
 
+type ErrorContext = {
+  status: number
+  message: string
+}
+
+// renderError renders the error page within the layout. Error pages aren't
+// hydrated because the client expects the page's props.
+function renderError(view: View, error: ErrorContext) {
+  const props = { status: error.status, message: error.message }
+  const page = (view.error || defaultError).render(props)
+  const layout = view.layout.render(props, {
+    head: function () {
+      return `
+        ${page.head}
+        <style>#bud{}${page.css.code}</style>
+      `
+    },
+    default: function () {
+      return '<div id="bud_target">' + page.html + "</div>"
+    },
+  })
+  return {
+    status: error.status,
+    headers: {
+      "Content-Type": "text/html",
+    },
+    body: layout.html.replace("#bud{}", layout.css.code),
+  }
+}
+
+const defaultError = {
+  render(props) {
+    return {
+      css: {
+        code: "",
+      },
+      head: "",
+      html: `<h1>${props.status}</h1><p>${escape(props.message)}</p>`,
+    }
+  },
+}
+
+function escape(text: string) {
+  return String(text)
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;")
+}
+
 const defaultLayout = {
   render(props, slots) {
     return {
@@ -40,6 +40,7 @@ func TestHello(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>hello</h1>")
 	is.NoErr(td.Exists("bud/internal/web/view/view.go"))
@@ -61,6 +62,7 @@ func TestHello(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>hi</h1>")
 	// Change svelte file
@@ -81,6 +83,7 @@ func TestHello(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>hola</h1>")
 	// Try the entrypoint
@@ -90,6 +93,7 @@ func TestHello(t *testing.T) {
 		HTTP/1.1 200 OK
 		Accept-Ranges: bytes
 		Content-Type: application/javascript
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "bud_target")
 	is.In(res.Body().String(), "\"hola\"")
@@ -100,6 +104,7 @@ func TestHello(t *testing.T) {
 	HTTP/1.1 200 OK
 	Accept-Ranges: bytes
 	Content-Type: application/javascript
+	Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "\"hola\"")
 	// Try an external node_module
@@ -109,6 +114,7 @@ func TestHello(t *testing.T) {
 	HTTP/1.1 200 OK
 	Accept-Ranges: bytes
 	Content-Type: application/javascript
+	Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "// node_modules/svelte/internal/index.mjs")
 	is.NoErr(app.Close())
@@ -141,6 +147,7 @@ func TestHelloEmbed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>hello</h1>")
 	// Change svelte file
@@ -161,6 +168,7 @@ func TestHelloEmbed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>hello</h1>")
 	// Try the entrypoint
@@ -170,6 +178,7 @@ func TestHelloEmbed(t *testing.T) {
 		HTTP/1.1 200 OK
 		Accept-Ranges: bytes
 		Content-Type: application/javascript
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "bud_target")
 	is.NoErr(app.Close())
@@ -212,6 +221,7 @@ func TestChunks(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>index</h1>")
 	// Try the index entrypoint
@@ -221,6 +231,7 @@ func TestChunks(t *testing.T) {
 		HTTP/1.1 200 OK
 		Accept-Ranges: bytes
 		Content-Type: application/javascript
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "bud_target")
 	// Ensure we have a show
@@ -230,6 +241,7 @@ func TestChunks(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>show</h1>")
 	// Try the show entrypoint
@@ -239,6 +251,7 @@ func TestChunks(t *testing.T) {
 		HTTP/1.1 200 OK
 		Accept-Ranges: bytes
 		Content-Type: application/javascript
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "bud_target")
 	// Ensure the code's been split and find the name of the chunk
@@ -250,6 +263,7 @@ func TestChunks(t *testing.T) {
 		HTTP/1.1 200 OK
 		Accept-Ranges: bytes
 		Content-Type: application/javascript
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "bud_props")
 	is.NoErr(app.Close())
@@ -284,6 +298,7 @@ func TestConsoleLog(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>hello</h1>")
 	is.NoErr(app.Close())
@@ -329,6 +344,7 @@ func TestRenameView(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>10</h1>")
 	// Rename the file
@@ -347,6 +363,7 @@ func TestRenameView(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 	`))
 	is.Equal(res.Body().String(), "10")
 	is.NoErr(app.Close())
@@ -377,6 +394,7 @@ func TestAddView(t *testing.T) {
 	is.NoErr(res.DiffHeaders(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 	`))
 	is.Equal(res.Body().String(), "10")
 	// Add the view
@@ -402,6 +420,7 @@ func TestAddView(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>10</h1>")
 }
@@ -435,6 +454,7 @@ func TestSvelteImportFromNodeModule(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<time datetime=\"2022-07-19 10:19:00\">Jul 19, 2022</time>")
 	is.NoErr(app.Close())
@@ -473,6 +493,7 @@ func TestSvelteImportFromOtherDir(t *testing.T) {
 		HTTP/1.1 200 OK
 		Transfer-Encoding: chunked
 		Content-Type: text/html
+		Vary: Accept-Encoding
 	`))
 	is.In(res.Body().String(), "<h1>The Time</h1>")
 	is.NoErr(app.Close())
@@ -2,6 +2,7 @@ package viewrt
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"github.com/livebud/bud/framework/view/ssr"
 	"github.com/livebud/bud/package/js"
 	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/middleware/csrf"
+	"github.com/livebud/bud/package/session"
 )
 
 type FS = fs.FS
@@ -43,8 +46,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Renderer(route string, props interface{}) http.Handler {
+	return h.renderer(route, props, false)
+}
+
+// FormRenderer renders a view that submits a form. Unlike Renderer, it also
+// passes the CSRF token to the view.
+func (h *Handler) FormRenderer(route string, props interface{}) http.Handler {
+	return h.renderer(route, props, true)
+}
+
+func (h *Handler) renderer(route string, props interface{}, form bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		res, err := h.render(route, props)
+		res, err := h.render(route, h.withSession(r, props, form), nil)
 		if err != nil {
 			h.log.Field("error", err).Error("view: render error")
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -59,17 +72,108 @@ func (h *Handler) Renderer(route string, props interface{}) http.Handler {
 	})
 }
 
-func (h *Handler) render(path string, props interface{}) (*ssr.Response, error) {
+// ErrorRenderer renders the nearest error view for route with the given status.
+// If there's no view at route, it falls back to a plain text error.
+func (h *Handler) ErrorRenderer(route string, status int, err error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := map[string]interface{}{
+			"error": map[string]interface{}{
+				"status":  status,
+				"message": err.Error(),
+			},
+		}
+		res, renderErr := h.render(route, nil, context)
+		if renderErr != nil {
+			h.log.Field("error", renderErr).Error("view: render error")
+			http.Error(w, err.Error(), status)
+			return
+		} else if res.Status == http.StatusNotFound && res.Body == "" {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		headers := w.Header()
+		for key, value := range res.Headers {
+			headers.Set(key, value)
+		}
+		w.WriteHeader(res.Status)
+		w.Write([]byte(res.Body))
+	})
+}
+
+// withSession adds the session's flash messages to the props as "flash" and,
+// for form views, the CSRF token as "csrf". The token is only created for form
+// views, so other views don't need to store it in the session. Props that
+// already have these keys are left alone. Struct props are merged by their JSON
+// fields, while props that aren't JSON objects don't receive either.
+func (h *Handler) withSession(r *http.Request, props interface{}, form bool) interface{} {
+	propMap, ok := toPropMap(props)
+	if !ok {
+		return props
+	}
+	sess, err := session.Load(r)
+	if err != nil {
+		if !errors.Is(err, session.ErrNoMiddleware) {
+			h.log.Field("error", err).Error("view: unable to load the session")
+		}
+		return props
+	}
+	merged := make(map[string]interface{}, len(propMap)+2)
+	for key, value := range propMap {
+		merged[key] = value
+	}
+	if _, ok := merged["flash"]; !ok {
+		if flashes := sess.Flashes(); len(flashes) > 0 {
+			merged["flash"] = flashes
+		}
+	}
+	if _, ok := merged["csrf"]; form && !ok {
+		token, err := csrf.Token(r)
+		if err != nil {
+			h.log.Field("error", err).Error("view: unable to create a CSRF token")
+		} else {
+			merged["csrf"] = token
+		}
+	}
+	return merged
+}
+
+// toPropMap returns the props as a map, so values can be added to them. Props
+// are passed to the view as JSON, so other props are converted to a map of
+// their JSON fields.
+func toPropMap(props interface{}) (map[string]interface{}, bool) {
+	if propMap, ok := props.(map[string]interface{}); ok {
+		return propMap, true
+	}
+	data, err := json.Marshal(props)
+	if err != nil {
+		return nil, false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, false
+	}
+	propMap := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		propMap[key] = value
+	}
+	return propMap, true
+}
+
+func (h *Handler) render(path string, props, context interface{}) (*ssr.Response, error) {
 	propBytes, err := json.Marshal(props)
 	if err != nil {
 		return nil, err
 	}
+	contextBytes, err := json.Marshal(context)
+	if err != nil {
+		return nil, err
+	}
 	script, err := fs.ReadFile(h.fsys, "bud/view/_ssr.js")
 	if err != nil {
 		return nil, err
 	}
 	// Evaluate the server
-	expr := fmt.Sprintf(`%s; bud.render(%q, %s)`, script, path, propBytes)
+	expr := fmt.Sprintf(`%s; bud.render(%q, %s, %s)`, script, path, propBytes, contextBytes)
 	result, err := h.vm.Eval("_ssr.js", expr)
 	if err != nil {
 		return nil, err
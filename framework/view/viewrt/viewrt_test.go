@@ -0,0 +1,62 @@
+package viewrt_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livebud/bud/framework/view/viewrt"
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/log/testlog"
+	"github.com/livebud/bud/package/session"
+	"github.com/livebud/bud/package/virtual"
+)
+
+// vm records the expression that renders the view
+type vm struct {
+	expr string
+}
+
+func (v *vm) Script(path, script string) error {
+	return nil
+}
+
+func (v *vm) Eval(path, expr string) (string, error) {
+	v.expr = expr
+	return `{"status":200,"body":"<h1>hello</h1>"}`, nil
+}
+
+func TestFlashStructProps(t *testing.T) {
+	is := is.New(t)
+	log := testlog.New()
+	fsys := virtual.Map{
+		"bud/view/_ssr.js": `var bud = {}`,
+	}
+	vm := new(vm)
+	view := viewrt.New(fsys, log, vm)
+	type Post struct {
+		Title string `json:"title"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(w http.ResponseWriter, r *http.Request) {
+		sess, err := session.Load(r)
+		is.NoErr(err)
+		sess.Flash("notice", "Post created")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.Handle("/", view.Renderer("/", &Post{Title: "hello"}))
+	handler := session.New(log, session.NewMemoryStore()).Middleware(mux)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/create", nil))
+	is.Equal(rec.Code, http.StatusNoContent)
+	cookies := rec.Result().Cookies()
+	is.Equal(len(cookies), 1)
+	// The flash is merged into the struct's JSON fields
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusOK)
+	is.Equal(rec.Body.String(), "<h1>hello</h1>")
+	is.In(vm.expr, `bud.render("/", {"flash":[{"type":"notice","message":"Post created"}],"title":"hello"}, null)`)
+}
@@ -1,9 +1,15 @@
 package web
 
 import (
+	"errors"
+	"fmt"
 	"io/fs"
+	"math"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/livebud/bud/package/valid"
 
@@ -18,6 +24,7 @@ import (
 func Load(fsys fs.FS, module *gomod.Module, parser *parser.Parser) (*State, error) {
 	loader := &loader{
 		imports: imports.New(),
+		params:  map[string]int{},
 		fsys:    fsys,
 		module:  module,
 		parser:  parser,
@@ -28,6 +35,7 @@ func Load(fsys fs.FS, module *gomod.Module, parser *parser.Parser) (*State, erro
 type loader struct {
 	bail.Struct
 	imports *imports.Set
+	params  map[string]int // Parameter names of the generated New function
 	fsys    fs.FS
 	module  *gomod.Module
 	parser  *parser.Parser
@@ -50,9 +58,19 @@ func (l *loader) Load() (state *State, err error) {
 	// Add initial imports
 	l.imports.AddStd("net/http", "context")
 	l.imports.AddNamed("middleware", "github.com/livebud/bud/package/middleware")
+	l.imports.AddNamed("requestlog", "github.com/livebud/bud/package/middleware/requestlog")
+	l.imports.AddNamed("recovery", "github.com/livebud/bud/package/middleware/recovery")
+	l.imports.AddNamed("compress", "github.com/livebud/bud/package/middleware/compress")
 	l.imports.AddNamed("methodoverride", "github.com/livebud/bud/package/middleware/methodoverride")
+	l.imports.AddNamed("csrf", "github.com/livebud/bud/package/middleware/csrf")
 	l.imports.AddNamed("webrt", "github.com/livebud/bud/framework/web/webrt")
 	l.imports.AddNamed("router", "github.com/livebud/bud/package/router")
+	l.imports.AddNamed("log", "github.com/livebud/bud/package/log")
+	l.imports.AddNamed("session", "github.com/livebud/bud/package/session")
+	// Reserve the parameters that are always passed in
+	l.param("log")
+	l.param("router")
+	l.param("sessionMiddleware")
 	// Show the welcome page if we don't have any web resources
 	showWelcome, err := shouldShowWelcome(l.fsys, webDirs)
 	if err != nil {
@@ -61,7 +79,7 @@ func (l *loader) Load() (state *State, err error) {
 	if showWelcome {
 		const importPath = "github.com/livebud/bud/framework/web/welcome"
 		state.Resources = append(state.Resources, &Resource{
-			Camel: "welcome",
+			Camel: l.param("welcome"),
 			Import: &imports.Import{
 				Name: l.imports.Add(importPath),
 				Path: importPath,
@@ -70,8 +88,14 @@ func (l *loader) Load() (state *State, err error) {
 	}
 	// Load the resources
 	for _, webDir := range webDirs {
-		state.Resources = append(state.Resources, l.loadResource(webDir))
+		resource := l.loadResource(webDir)
+		if webDir == "bud/internal/web/view" {
+			state.View = resource
+		}
+		state.Resources = append(state.Resources, resource)
 	}
+	// Load the app's middleware
+	state.Middleware = l.loadMiddlewares()
 	// Load the imports
 	state.Imports = l.imports.List()
 	return state, nil
@@ -85,10 +109,119 @@ func (l *loader) loadResource(webDir string) (resource *Resource) {
 		Path: importPath,
 	}
 	packageName := path.Base(webDir)
-	resource.Camel = gotext.Camel(packageName)
+	resource.Camel = l.param(gotext.Camel(packageName))
 	return resource
 }
 
+// param returns a unique parameter name for the generated New function
+func (l *loader) param(name string) string {
+	ith := l.params[name]
+	l.params[name]++
+	if ith == 0 {
+		return name
+	}
+	return name + strconv.Itoa(ith+1)
+}
+
+// loadMiddlewares loads the packages within middleware/. The middleware runs in
+// the order of the numeric prefix of the directory names, so "2_auth" runs
+// before "10_cache". Directories without a prefix run last, ordered by name.
+func (l *loader) loadMiddlewares() (middlewares []*Middleware) {
+	des, err := fs.ReadDir(l.fsys, "middleware")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		l.Bail(err)
+	}
+	// ReadDir returns the entries sorted by name, so entries with the same
+	// prefix stay ordered by name
+	sort.SliceStable(des, func(i, j int) bool {
+		return middlewareOrder(des[i].Name()) < middlewareOrder(des[j].Name())
+	})
+	for _, de := range des {
+		if !de.IsDir() || !valid.Dir(de.Name()) {
+			continue
+		}
+		middleware := l.loadMiddleware(path.Join("middleware", de.Name()))
+		if middleware == nil {
+			continue
+		}
+		middlewares = append(middlewares, middleware)
+	}
+	return middlewares
+}
+
+func (l *loader) loadMiddleware(dir string) *Middleware {
+	if files, err := fs.Glob(l.fsys, path.Join(dir, "*.go")); err != nil {
+		l.Bail(err)
+	} else if len(files) == 0 {
+		return nil
+	}
+	pkg, err := l.parser.Parse(dir)
+	if err != nil {
+		l.Bail(err)
+	}
+	stct := l.findMiddleware(pkg)
+	if stct == nil {
+		l.Bail(fmt.Errorf("web: unable to find a type with a Middleware(next http.Handler) http.Handler method in %q", dir))
+	}
+	importPath := l.module.Import(dir)
+	name := l.imports.AddNamed(pkg.Name(), importPath)
+	middleware := new(Middleware)
+	middleware.Import = &imports.Import{
+		Name: name,
+		Path: importPath,
+	}
+	middleware.Camel = l.param(gotext.Camel(strings.TrimLeft(path.Base(dir), "0123456789_-") + " middleware"))
+	middleware.Type = "*" + name + "." + stct.Name()
+	return middleware
+}
+
+// middlewareOrder returns the numeric prefix of a middleware directory, like 10
+// for "10_cache". Directories without a prefix are ordered last.
+func middlewareOrder(dir string) int {
+	digits := len(dir) - len(strings.TrimLeft(dir, "0123456789"))
+	if digits == 0 {
+		return math.MaxInt
+	}
+	n, err := strconv.Atoi(dir[:digits])
+	if err != nil {
+		return math.MaxInt
+	}
+	return n
+}
+
+// findMiddleware finds the struct with the Middleware method
+func (l *loader) findMiddleware(pkg *parser.Package) *parser.Struct {
+	for _, stct := range pkg.Structs() {
+		if stct.Private() {
+			continue
+		}
+		method := stct.Method("Middleware")
+		if method == nil || method.Private() {
+			continue
+		}
+		params, results := method.Params(), method.Results()
+		if len(params) != 1 || len(results) != 1 {
+			continue
+		}
+		if !l.isHandler(params[0].Type()) || !l.isHandler(results[0].Type()) {
+			continue
+		}
+		return stct
+	}
+	return nil
+}
+
+func (l *loader) isHandler(dt parser.Type) bool {
+	isHandler, err := parser.IsImportType(dt, "net/http", "Handler")
+	if err != nil {
+		l.Bail(err)
+	}
+	return isHandler
+}
+
 func shouldShowWelcome(fsys fs.FS, webDirs []string) (bool, error) {
 	if len(webDirs) == 0 {
 		return true, nil
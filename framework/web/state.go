@@ -3,8 +3,10 @@ package web
 import "github.com/livebud/bud/package/imports"
 
 type State struct {
-	Imports   []*imports.Import
-	Resources []*Resource
+	Imports    []*imports.Import
+	Resources  []*Resource
+	Middleware []*Middleware
+	View       *Resource // View renders error pages for panics
 }
 
 // Resource is a web package that will register its routes
@@ -12,3 +14,10 @@ type Resource struct {
 	Import *imports.Import
 	Camel  string
 }
+
+// Middleware is an app package in middleware/ that wraps the router
+type Middleware struct {
+	Import *imports.Import
+	Camel  string
+	Type   string // Qualified type with the Middleware method
+}
@@ -24,3 +24,168 @@ func TestEmptyBuild(t *testing.T) {
 	// Empty builds generate the web directory
 	is.NoErr(td.Exists("bud/internal/web"))
 }
+
+func TestMiddleware(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string {
+			return "hello"
+		}
+	`
+	// Directory prefixes order the middleware
+	td.Files["middleware/1_trace/trace.go"] = `
+		package trace
+		import "net/http"
+		type Trace struct {}
+		func (t *Trace) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Header.Set("X-Trace", "trace")
+				next.ServeHTTP(w, r)
+			})
+		}
+	`
+	td.Files["middleware/2_auth/auth.go"] = `
+		package auth
+		import "net/http"
+		func New() *Auth {
+			return &Auth{"secret"}
+		}
+		type Auth struct {
+			token string
+		}
+		func (a *Auth) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Trace") != "trace" {
+					http.Error(w, "trace must run first", http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("X-Auth", a.token)
+				next.ServeHTTP(w, r)
+			})
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Auth"), "secret")
+	is.NoErr(app.Close())
+}
+
+func TestMiddlewareNumericOrder(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string {
+			return "hello"
+		}
+	`
+	// 2_trace runs before 10_auth even though "10" sorts before "2"
+	td.Files["middleware/2_trace/trace.go"] = `
+		package trace
+		import "net/http"
+		type Trace struct {}
+		func (t *Trace) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Header.Set("X-Trace", "trace")
+				next.ServeHTTP(w, r)
+			})
+		}
+	`
+	td.Files["middleware/10_auth/auth.go"] = `
+		package auth
+		import "net/http"
+		type Auth struct {}
+		func (a *Auth) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Trace") != "trace" {
+					http.Error(w, "trace must run first", http.StatusInternalServerError)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.NoErr(app.Close())
+}
+
+func TestMiddlewareSameName(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/controller.go"] = `
+		package controller
+		type Controller struct {}
+		func (c *Controller) Index() string {
+			return "hello"
+		}
+	`
+	// Each middleware sets a header, so they all need to run
+	middleware := func(pkg, header string) string {
+		return `
+			package ` + pkg + `
+			import "net/http"
+			type Middleware struct {}
+			func (m *Middleware) Middleware(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("` + header + `", "true")
+					next.ServeHTTP(w, r)
+				})
+			}
+		`
+	}
+	// "session" would collide with the built-in session middleware
+	td.Files["middleware/session/session.go"] = middleware("session", "X-Session")
+	td.Files["middleware/1_auth/auth.go"] = middleware("auth", "X-Auth-1")
+	td.Files["middleware/2_auth/auth.go"] = middleware("auth", "X-Auth-2")
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	app, err := cli.Start(ctx, "run")
+	is.NoErr(err)
+	defer app.Close()
+	res, err := app.Get("/")
+	is.NoErr(err)
+	is.Equal(res.Status(), 200)
+	is.Equal(res.Header("X-Session"), "true")
+	is.Equal(res.Header("X-Auth-1"), "true")
+	is.Equal(res.Header("X-Auth-2"), "true")
+	is.NoErr(app.Close())
+}
+
+func TestMiddlewareMissingMethod(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["middleware/auth/auth.go"] = `
+		package auth
+		type Auth struct {}
+	`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	_, err = cli.Run(ctx, "build")
+	is.True(err != nil)
+	is.In(err.Error(), `unable to find a type with a Middleware(next http.Handler) http.Handler method in "middleware/auth"`)
+}
@@ -170,6 +170,13 @@ func (c *CLI) Parse(ctx context.Context, args ...string) error {
 		cli.Run(func(ctx context.Context) error { return c.Build(ctx, in) })
 	}
 
+	{ // $ bud routes
+		in := &Routes{Flag: &framework.Flag{}}
+		cli := cli.Command("routes", "list the app's routes")
+		cli.Flag("json", "print the routes as JSON").Bool(&in.JSON).Default(false)
+		cli.Run(func(ctx context.Context) error { return c.Routes(ctx, in) })
+	}
+
 	{ // $ bud new <dir>
 		in := &Create{}
 		cli := cli.Command("new", "scaffold code for your app")
@@ -24,6 +24,30 @@ var fileGenerators = map[string]generator.Selector{
 		Import: "github.com/livebud/bud/framework/public",
 		Type:   "*Generator",
 	},
+	"bud/internal/web/openapi/openapi.go": {
+		Import: "github.com/livebud/bud/framework/openapi",
+		Type:   "*Generator",
+	},
+	"bud/openapi.json": {
+		Import: "github.com/livebud/bud/framework/openapi/spec",
+		Type:   "*Generator",
+	},
+	"bud/client/client.go": {
+		Import: "github.com/livebud/bud/framework/client",
+		Type:   "*Generator",
+	},
+	"bud/client.ts": {
+		Import: "github.com/livebud/bud/framework/tsclient",
+		Type:   "*Generator",
+	},
+	"bud/routes/routes.go": {
+		Import: "github.com/livebud/bud/framework/routes",
+		Type:   "*Generator",
+	},
+	"bud/routes.ts": {
+		Import: "github.com/livebud/bud/framework/tsroutes",
+		Type:   "*Generator",
+	},
 	"bud/view/_ssr.js": {
 		Import: "github.com/livebud/bud/framework/view/ssr",
 		Type:   "*Generator",
@@ -35,6 +35,7 @@ func TestNewControllerNoActions(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 404 Not Found
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 		X-Content-Type-Options: nosniff
 
 		404 page not found
@@ -63,6 +64,7 @@ func TestNewControllerNoActionsRoute(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 404 Not Found
 		Content-Type: text/plain; charset=utf-8
+		Vary: Accept-Encoding
 		X-Content-Type-Options: nosniff
 
 		404 page not found
@@ -115,7 +117,9 @@ func TestNewControllerAll(t *testing.T) {
 	is.In(html, `<a href="/posts">Back</a>`)
 
 	// Create post
-	res, err = app.Post("/posts", nil)
+	req, err := app.PostRequest("/posts", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -138,7 +142,9 @@ func TestNewControllerAll(t *testing.T) {
 	is.In(html, `<a href="/posts">Back</a>`)
 
 	// Update post with patch
-	res, err = app.Patch("/posts/10", nil)
+	req, err = app.PatchRequest("/posts/10", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -147,10 +153,10 @@ func TestNewControllerAll(t *testing.T) {
 	// Update post using method override
 	values := url.Values{}
 	values.Set("_method", http.MethodPatch)
-	req, err := app.PostRequest("/posts/10", bytes.NewBufferString(values.Encode()))
+	req, err = app.PostRequest("/posts/10", bytes.NewBufferString(values.Encode()))
 	is.NoErr(err)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	res, err = app.Do(req)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -158,7 +164,9 @@ func TestNewControllerAll(t *testing.T) {
 	`))
 
 	// Delete post
-	res, err = app.Delete("/posts/10", nil)
+	req, err = app.DeleteRequest("/posts/10", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -213,7 +221,9 @@ func TestNewControllerAllRoot(t *testing.T) {
 	is.In(html, `<a href="/">Back</a>`)
 
 	// Create post
-	res, err = app.Post("/", nil)
+	req, err := app.PostRequest("/", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -236,7 +246,9 @@ func TestNewControllerAllRoot(t *testing.T) {
 	is.In(html, `<a href="/">Back</a>`)
 
 	// Update post with patch
-	res, err = app.Patch("/10", nil)
+	req, err = app.PatchRequest("/10", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -245,10 +257,10 @@ func TestNewControllerAllRoot(t *testing.T) {
 	// Update post using method override
 	values := url.Values{}
 	values.Set("_method", http.MethodPatch)
-	req, err := app.PostRequest("/10", bytes.NewBufferString(values.Encode()))
+	req, err = app.PostRequest("/10", bytes.NewBufferString(values.Encode()))
 	is.NoErr(err)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	res, err = app.Do(req)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -256,7 +268,9 @@ func TestNewControllerAllRoot(t *testing.T) {
 	`))
 
 	// Delete post
-	res, err = app.Delete("/10", nil)
+	req, err = app.DeleteRequest("/10", nil)
+	is.NoErr(err)
+	res, err = app.Do(testcli.SameOrigin(req))
 	is.NoErr(err)
 	is.NoErr(res.Diff(`
 		HTTP/1.1 302 Found
@@ -418,6 +432,7 @@ func TestNewControllerRemoveViewDir(t *testing.T) {
 	is.NoErr(res.Diff(`
 		HTTP/1.1 200 OK
 		Content-Type: application/json
+		Vary: Accept-Encoding
 
 		[]
 	`))
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/livebud/bud/framework"
+	"github.com/livebud/bud/internal/dag"
+	"github.com/livebud/bud/internal/routetable"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/parser"
+	"github.com/livebud/bud/package/virtual"
+)
+
+type Routes struct {
+	Flag *framework.Flag
+	JSON bool
+}
+
+// Routes prints the app's route table
+func (c *CLI) Routes(ctx context.Context, in *Routes) error {
+	log, err := c.loadLog()
+	if err != nil {
+		return err
+	}
+	module, err := c.findModule()
+	if err != nil {
+		return err
+	}
+	// Load the route table from the app's files without generating bud/
+	fsys := genfs.New(dag.Discard, virtual.Exclude(module, func(path string) bool {
+		return path == "bud" || strings.HasPrefix(path, "bud/")
+	}), log)
+	parser := parser.New(fsys, module)
+	injector := di.New(fsys, log, module, parser)
+	var routes []*routetable.Route
+	fsys.GenerateFile("bud/routes.json", func(fsys genfs.FS, file *genfs.File) (err error) {
+		routes, err = routetable.Load(fsys, injector, module, parser, in.Flag)
+		if err != nil {
+			return err
+		}
+		if routes == nil {
+			routes = []*routetable.Route{}
+		}
+		file.Data, err = json.MarshalIndent(routes, "", "  ")
+		return err
+	})
+	data, err := fs.ReadFile(fsys, "bud/routes.json")
+	if err != nil {
+		return err
+	}
+	if in.JSON {
+		fmt.Fprintln(c.Stdout, string(data))
+		return nil
+	}
+	tw := tabwriter.NewWriter(c.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tROUTE\tHANDLER\tORIGIN\tFORMAT")
+	for _, route := range routes {
+		origin := route.Origin
+		if route.View != "" {
+			origin += " (" + route.View + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Route, route.Handler, origin, route.Format)
+	}
+	return tw.Flush()
+}
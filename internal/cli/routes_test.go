@@ -0,0 +1,87 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/internal/routetable"
+	"github.com/livebud/bud/internal/testcli"
+	"github.com/livebud/bud/package/testdir"
+)
+
+const postsController = `
+	package posts
+	import "net/http"
+	type Controller struct {}
+	type Post struct {
+		ID int ` + "`" + `json:"id"` + "`" + `
+	}
+	func (c *Controller) Index() []*Post {
+		return nil
+	}
+	func (c *Controller) Show(id int) *Post {
+		return &Post{id}
+	}
+	func (c *Controller) Create() (*Post, error) {
+		return &Post{1}, nil
+	}
+	func (c *Controller) Export(w http.ResponseWriter, r *http.Request) {}
+`
+
+func TestRoutes(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/posts/controller.go"] = postsController
+	td.Files["view/posts/index.svelte"] = `<h1>posts</h1>`
+	td.Files["public/favicon.ico"] = `favicon`
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	result, err := cli.Run(ctx, "routes")
+	is.NoErr(err)
+	stdout := result.Stdout()
+	is.In(stdout, "METHOD  ROUTE")
+	is.In(stdout, "/posts/index (view/posts/index.svelte)")
+	is.In(stdout, "/posts/show")
+	is.In(stdout, "/posts/export")
+	is.In(stdout, "/bud/view/posts/_index.svelte.js")
+	is.In(stdout, "/favicon.ico")
+	// The dev server serves hot reloads, not the app
+	is.NotIn(stdout, "/bud/hot/:page*")
+	is.NoErr(td.NotExists("bud/app"))
+}
+
+func TestRoutesJSON(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["controller/posts/controller.go"] = postsController
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	result, err := cli.Run(ctx, "routes", "--json")
+	is.NoErr(err)
+	var routes []*routetable.Route
+	is.NoErr(json.Unmarshal([]byte(result.Stdout()), &routes))
+	is.Equal(len(routes), 5)
+	is.Equal(*routes[0], routetable.Route{Method: "GET", Route: "/openapi.json", Handler: "openapi", Origin: "bud/openapi.json", Format: "json"})
+	is.Equal(*routes[1], routetable.Route{Method: "GET", Route: "/posts", Handler: "controller", Origin: "/posts/index", Format: "json"})
+	is.Equal(*routes[2], routetable.Route{Method: "POST", Route: "/posts", Handler: "controller", Origin: "/posts/create", Format: "html,json"})
+	is.Equal(*routes[3], routetable.Route{Method: "GET", Route: "/posts/:id", Handler: "controller", Origin: "/posts/show", Format: "json"})
+	is.Equal(*routes[4], routetable.Route{Method: "GET", Route: "/posts/export", Handler: "controller", Origin: "/posts/export", Format: "custom"})
+}
+
+func TestRoutesEmpty(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	is.NoErr(td.Write(ctx))
+	cli := testcli.New(td.Directory())
+	result, err := cli.Run(ctx, "routes", "--json")
+	is.NoErr(err)
+	is.Equal(result.Stdout(), "[]\n")
+}
@@ -0,0 +1,120 @@
+// Package routetable lists the routes that an app registers in development.
+package routetable
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/livebud/bud/framework"
+	"github.com/livebud/bud/framework/controller"
+	"github.com/livebud/bud/framework/openapi"
+	"github.com/livebud/bud/framework/public"
+	"github.com/livebud/bud/internal/entrypoint"
+	"github.com/livebud/bud/package/di"
+	"github.com/livebud/bud/package/gomod"
+	"github.com/livebud/bud/package/parser"
+)
+
+// Route in the route table
+type Route struct {
+	Method  string `json:"method"`
+	Route   string `json:"route"`
+	Handler string `json:"handler"`        // controller, view, public or openapi
+	Origin  string `json:"origin"`         // Action key, view page or public file
+	View    string `json:"view,omitempty"` // View page that the action renders
+	Format  string `json:"format,omitempty"`
+}
+
+// Load the route table from the controllers, views and public files
+func Load(fsys fs.FS, injector *di.Injector, module *gomod.Module, parser *parser.Parser, flag *framework.Flag) ([]*Route, error) {
+	var routes []*Route
+	// Load the controller actions
+	state, err := controller.Load(fsys, injector, module, parser)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if state != nil {
+		routes = append(routes, loadController(state.Controller)...)
+		// The OpenAPI document is served alongside the controllers in development
+		if !flag.Embed {
+			routes = append(routes, &Route{
+				Method:  "GET",
+				Route:   openapi.Route,
+				Handler: "openapi",
+				Origin:  "bud/openapi.json",
+				Format:  "json",
+			})
+		}
+	}
+	// Load the view assets
+	views, err := entrypoint.List(fsys, "view")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(views) > 0 {
+		for _, view := range views {
+			routes = append(routes,
+				&Route{Method: "GET", Route: "/" + view.Client, Handler: "view", Origin: string(view.Page), Format: "js"},
+				&Route{Method: "GET", Route: "/bud/" + string(view.Page), Handler: "view", Origin: string(view.Page), Format: "js"},
+			)
+		}
+		routes = append(routes, &Route{Method: "GET", Route: "/bud/node_modules/:module*", Handler: "view", Origin: "node_modules", Format: "js"})
+	}
+	// Load the public files
+	publicState, err := public.Load(fsys, flag)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if publicState != nil {
+		for _, file := range publicState.Files {
+			routes = append(routes, &Route{Method: "GET", Route: file.Route, Handler: "public", Origin: file.Path, Format: "file"})
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Route < routes[j].Route
+	})
+	return routes, nil
+}
+
+func loadController(controller *controller.Controller) (routes []*Route) {
+	for _, action := range controller.Actions {
+		route := &Route{
+			Method:  strings.ToUpper(action.Method),
+			Route:   action.Route,
+			Handler: "controller",
+			Origin:  action.Key,
+			Format:  format(action),
+		}
+		if action.View != nil {
+			route.View = action.View.Path
+		}
+		routes = append(routes, route)
+	}
+	for _, subController := range controller.Controllers {
+		routes = append(routes, loadController(subController)...)
+	}
+	return routes
+}
+
+// format returns what the action responds with, following the generated
+// controller's response logic
+func format(action *controller.Action) string {
+	switch {
+	case action.HandlerFunc:
+		return "custom"
+	case action.Socket:
+		return "websocket"
+	case action.Results.Stream() == "Events" || action.Results.Stream() == "Iterate":
+		return "event-stream"
+	case action.Results.Stream() != "":
+		return "stream"
+	// Non-GET actions and actions with views respond to HTML requests too.
+	// Form errors re-render the form view or render the nearest error page.
+	case action.Method != "Get" || action.View != nil || action.RespondHTML:
+		return "html,json"
+	default:
+		return "json"
+	}
+}
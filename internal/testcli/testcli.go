@@ -26,6 +26,7 @@ import (
 	"github.com/livebud/bud/package/log/testlog"
 	"github.com/livebud/bud/package/socket"
 
+	"golang.org/x/net/websocket"
 	"golang.org/x/sync/errgroup"
 
 	cli "github.com/livebud/bud/internal/cli"
@@ -185,6 +186,7 @@ func (c *CLI) Start(ctx context.Context, args ...string) (*Client, error) {
 		stdout: stdout,
 		stderr: stderr,
 		webc:   webc,
+		webLn:  webLn,
 		hotc:   budc,
 		// Close function
 		close: func() error {
@@ -210,6 +212,7 @@ type Client struct {
 	stdout *bytes.Buffer
 	stderr *bytes.Buffer
 	webc   *http.Client
+	webLn  socket.Listener
 	hotc   *http.Client
 	once   once.Error
 	close  func() error
@@ -388,10 +391,40 @@ func (c *Client) GetJSON(path string) (*Response, error) {
 	return do(c.webc, req)
 }
 
+// Dial a WebSocket connection to the running app
+func (c *Client) Dial(ctx context.Context, path string) (*websocket.Conn, error) {
+	c.log.Debug("testcli: dial websocket %q", path)
+	config, err := websocket.NewConfig("ws://host"+path, getURL("/"))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := socket.Dial(ctx, c.webLn.Addr().String())
+	if err != nil {
+		return nil, err
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
+
 func getRequest(path string) (*http.Request, error) {
 	return http.NewRequest(http.MethodGet, getURL(path), nil)
 }
 
+// SameOrigin marks the request as coming from the app's own pages, like a form
+// submitted in the browser, so it passes the CSRF middleware
+func SameOrigin(req *http.Request) *http.Request {
+	req.Header.Set("Sec-Fetch-Site", "same-origin")
+	return req
+}
+
+func (c *Client) GetRequest(path string) (*http.Request, error) {
+	return getRequest(path)
+}
+
 func (c *Client) Post(path string, body io.Reader) (*Response, error) {
 	c.log.Debug("testcli: post request %q", path)
 	req, err := c.PostRequest(path, body)
@@ -440,6 +473,30 @@ func (c *Client) PatchRequest(path string, body io.Reader) (*http.Request, error
 	return http.NewRequest(http.MethodPatch, getURL(path), body)
 }
 
+func (c *Client) Put(path string, body io.Reader) (*Response, error) {
+	c.log.Debug("testcli: put request %q", path)
+	req, err := c.PutRequest(path, body)
+	if err != nil {
+		return nil, err
+	}
+	return do(c.webc, req)
+}
+
+func (c *Client) PutJSON(path string, body io.Reader) (*Response, error) {
+	c.log.Debug("testcli: put json request %q", path)
+	req, err := c.PutRequest(path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return do(c.webc, req)
+}
+
+func (c *Client) PutRequest(path string, body io.Reader) (*http.Request, error) {
+	return http.NewRequest(http.MethodPut, getURL(path), body)
+}
+
 func (c *Client) Delete(path string, body io.Reader) (*Response, error) {
 	c.log.Debug("testcli: delete request %q", path)
 	req, err := c.DeleteRequest(path, body)
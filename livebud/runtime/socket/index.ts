@@ -0,0 +1,110 @@
+/**
+ * Connect to a controller action that accepts a WebSocket connection.
+ * Messages are encoded as JSON in both directions.
+ */
+
+export function connect<Send = any, Receive = any>(
+  path: string,
+  options: Options = {}
+): Socket<Send, Receive> {
+  return new Socket(socketURL(path), options)
+}
+
+export type Options = {
+  // Reconnect when the connection drops, defaults to true
+  reconnect?: boolean
+  // Maximum delay between reconnects in milliseconds, defaults to 10s
+  maxDelay?: number
+}
+
+type Listener<T> = (message: T) => void
+
+/**
+ * Socket that reconnects with backoff and queues messages while it's
+ * connecting.
+ */
+
+export class Socket<Send = any, Receive = any> {
+  private ws!: WebSocket
+  private subs: Array<Listener<Receive>> = []
+  private queue: string[] = []
+  private attempts = 0
+  private closed = false
+  private timer?: ReturnType<typeof setTimeout>
+
+  constructor(private readonly url: string, private readonly options: Options) {
+    this.open()
+  }
+
+  // Send a message, queueing it until the socket is open
+  send(message: Send) {
+    const data = JSON.stringify(message)
+    if (this.ws.readyState !== WebSocket.OPEN) {
+      this.queue.push(data)
+      return
+    }
+    this.ws.send(data)
+  }
+
+  // Listen for messages. Returns a function to stop listening.
+  listen(fn: Listener<Receive>): () => void {
+    this.subs.push(fn)
+    return () => {
+      this.subs = this.subs.filter((sub) => sub !== fn)
+    }
+  }
+
+  close() {
+    this.closed = true
+    clearTimeout(this.timer)
+    this.ws.close()
+  }
+
+  private open() {
+    this.ws = new WebSocket(this.url)
+    this.ws.addEventListener("open", this.onopen)
+    this.ws.addEventListener("message", this.onmessage)
+    this.ws.addEventListener("close", this.onclose)
+  }
+
+  private onopen = () => {
+    this.attempts = 0
+    let data: string | undefined
+    while ((data = this.queue.shift())) {
+      this.ws.send(data)
+    }
+  }
+
+  private onmessage = (e: MessageEvent) => {
+    let message: Receive
+    try {
+      message = JSON.parse(e.data)
+    } catch (err) {
+      console.error(err)
+      return
+    }
+    for (let sub of this.subs) {
+      sub(message)
+    }
+  }
+
+  private onclose = () => {
+    this.ws.removeEventListener("open", this.onopen)
+    this.ws.removeEventListener("message", this.onmessage)
+    this.ws.removeEventListener("close", this.onclose)
+    if (this.closed || this.options.reconnect === false) {
+      return
+    }
+    // Back off exponentially, up to the maximum delay
+    const maxDelay = this.options.maxDelay || 10000
+    const delay = Math.min(maxDelay, 250 * 2 ** this.attempts++)
+    this.timer = setTimeout(() => this.open(), delay)
+  }
+}
+
+// socketURL resolves the path against the current page, switching to ws: or wss:
+function socketURL(path: string): string {
+  const url = new URL(path, window.location.href)
+  url.protocol = url.protocol === "https:" ? "wss:" : "ws:"
+  return url.toString()
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/livebud/bud/package/budhttp"
 	"github.com/livebud/bud/package/hot"
 	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/middleware/cors"
 	"github.com/livebud/bud/package/virtual"
 
 	"github.com/livebud/bud/internal/pubsub"
@@ -36,7 +37,8 @@ func newHandler(flag *framework.Flag, fsys fs.FS, bus pubsub.Client, log log.Log
 	router.Get("/open/:path*", http.HandlerFunc(server.open))
 	// Routes that are directly requested by the browser to
 	if flag.Hot {
-		router.Get("/bud/hot/:page*", hot.New(log, bus))
+		// The page is served from the app's origin
+		router.Group("/bud/hot", cors.New()).Get("/:page*", hot.New(log, bus))
 	}
 	// Private routes between the app and bud
 	router.Post("/bud/events", http.HandlerFunc(server.publish))
@@ -79,7 +81,8 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	route := "/" + r.URL.Query().Get("route")
+	route, _ := router.Slots(r).Get("route")
+	route = "/" + route
 	expr := fmt.Sprintf(`%s; bud.render(%q, %s)`, script, route, body)
 	result, err := h.vm.Eval("_ssr.js", expr)
 	if err != nil {
@@ -90,7 +93,7 @@ func (h *Handler) render(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) open(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Query().Get("path")
+	path, _ := router.Slots(r).Get("path")
 	h.log.Field("file", path).Debug("devserver: opening")
 	file, err := h.fsys.Open(path)
 	if err != nil {
@@ -3,11 +3,16 @@ package budsvr_test
 import (
 	"context"
 	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/livebud/bud/framework"
 	"github.com/livebud/bud/internal/is"
 	"github.com/livebud/bud/internal/pubsub"
+	"github.com/livebud/bud/package/budhttp"
 	"github.com/livebud/bud/package/budhttp/budsvr"
 	v8 "github.com/livebud/bud/package/js/v8"
 	"github.com/livebud/bud/package/log/testlog"
@@ -144,3 +149,40 @@ func TestServerWaitNoServe(t *testing.T) {
 	is.NoErr(server.Wait())
 	is.NoErr(server.Wait())
 }
+
+func TestOpenAndRender(t *testing.T) {
+	is := is.New(t)
+	log := testlog.New()
+	fsys := virtual.Map{
+		"view/posts/show.svelte": `<h1>show</h1>`,
+		"bud/view/_ssr.js":       `var bud = { render: function(route, props) { return JSON.stringify({ route: route, props: props }) } }`,
+	}
+	vm, err := v8.Load()
+	is.NoErr(err)
+	bus := pubsub.New()
+	budln, err := socket.Listen(":0")
+	is.NoErr(err)
+	defer budln.Close()
+	flag := new(framework.Flag)
+	server := budsvr.New(budln, bus, flag, fsys, log, vm)
+	server.Start(context.Background())
+	defer server.Close()
+	client, err := budhttp.Load(log, server.Address())
+	is.NoErr(err)
+	// Open reads the path from the wildcard slot
+	remote, ok := client.(fs.FS)
+	is.True(ok)
+	code, err := fs.ReadFile(remote, "view/posts/show.svelte")
+	is.NoErr(err)
+	is.Equal(string(code), `<h1>show</h1>`)
+	_, err = fs.ReadFile(remote, "view/posts/edit.svelte")
+	is.True(errors.Is(err, fs.ErrNotExist))
+	// Render reads the route from the wildcard slot
+	res, err := http.Post("http://"+server.Address()+"/bud/view/posts/:id", "application/json", strings.NewReader(`{"id":1}`))
+	is.NoErr(err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, 200)
+	is.Equal(string(body), `{"route":"/posts/:id","props":{"id":1}}`)
+}
@@ -40,7 +40,6 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	headers.Add(`Content-Type`, `text/event-stream`)
 	headers.Add(`Cache-Control`, `no-cache`)
 	headers.Add(`Connection`, `keep-alive`)
-	headers.Add(`Access-Control-Allow-Origin`, "*")
 	// Flush the headers
 	flusher.Flush()
 	// Subscribe to a specific page path or all pages
@@ -139,9 +139,16 @@ func AssumedName(importPath string) string {
 		}
 	}
 	base = strings.TrimPrefix(base, "go-")
+	// Identifiers can't start with a digit (e.g. middleware/1_logger)
+	base = strings.TrimLeftFunc(base, func(ch rune) bool {
+		return !unicode.IsLetter(ch)
+	})
 	if i := strings.IndexFunc(base, notIdentifier); i >= 0 {
 		base = base[:i]
 	}
+	if base == "" {
+		return "pkg"
+	}
 	return base
 }
 
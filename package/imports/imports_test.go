@@ -15,6 +15,15 @@ func TestAdd(t *testing.T) {
 	is.Equal(im.Add("hop/http"), "http1")
 }
 
+func TestAddNumbered(t *testing.T) {
+	is := is.New(t)
+	im := imports.New()
+	is.Equal(im.Add("app.com/middleware/1_logger"), "logger")
+	is.Equal(im.Add("app.com/middleware/2_auth"), "auth")
+	is.Equal(im.Add("app.com/middleware/10-auth"), "auth1")
+	is.Equal(im.Add("app.com/middleware/123"), "pkg")
+}
+
 func TestAddNamed(t *testing.T) {
 	is := is.New(t)
 	im := imports.New()
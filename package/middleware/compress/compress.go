@@ -0,0 +1,336 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/livebud/bud/package/middleware"
+)
+
+// Option to configure compression
+type Option = func(o *option)
+
+type option struct {
+	MinSize int
+	Level   int
+}
+
+// WithMinSize sets the smallest response body in bytes that's compressed.
+// Defaults to 1024 bytes.
+func WithMinSize(size int) Option {
+	return func(o *option) {
+		o.MinSize = size
+	}
+}
+
+// WithLevel sets the gzip compression level. Defaults to
+// gzip.DefaultCompression.
+func WithLevel(level int) Option {
+	return func(o *option) {
+		o.Level = level
+	}
+}
+
+// New compression middleware that gzips responses for clients that accept it.
+// Small responses, responses that are already compressed and content types
+// that don't shrink, like images and videos, are sent as-is. Brotli isn't
+// supported because there's no brotli encoder in the standard library.
+func New(options ...Option) middleware.Middleware {
+	opt := &option{
+		MinSize: 1024,
+		Level:   gzip.DefaultCompression,
+	}
+	for _, option := range options {
+		option(opt)
+	}
+	// Invalid levels fall back to the default rather than failing every request
+	if _, err := gzip.NewWriterLevel(io.Discard, opt.Level); err != nil {
+		opt.Level = gzip.DefaultCompression
+	}
+	pool := &sync.Pool{
+		New: func() interface{} {
+			gz, _ := gzip.NewWriterLevel(io.Discard, opt.Level)
+			return gz
+		},
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cw := &writer{
+				w:       w,
+				r:       r,
+				option:  opt,
+				pool:    pool,
+				accepts: acceptsGzip(r.Header.Get("Accept-Encoding")),
+			}
+			next.ServeHTTP(wrap(w, cw), r)
+			// The response has started by now, so there's no way to report errors
+			cw.Close()
+		})
+	}
+}
+
+// wrap the response writer, keeping interfaces like http.Flusher and
+// http.Hijacker that the original response writer implements
+func wrap(w http.ResponseWriter, cw *writer) http.ResponseWriter {
+	return httpsnoop.Wrap(w, httpsnoop.Hooks{
+		WriteHeader: func(httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+			return cw.WriteHeader
+		},
+		Write: func(httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+			return cw.Write
+		},
+		ReadFrom: func(httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+			return func(src io.Reader) (int64, error) {
+				return io.Copy(writeOnly{cw}, src)
+			}
+		},
+		Flush: func(flush httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+			return func() {
+				cw.Flush()
+				flush()
+			}
+		},
+	})
+}
+
+// writeOnly hides ReadFrom so io.Copy calls Write
+type writeOnly struct {
+	io.Writer
+}
+
+// writer buffers the start of the response until it knows whether or not to
+// compress it
+type writer struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	option  *option
+	pool    *sync.Pool
+	accepts bool
+
+	status  int
+	buf     []byte
+	started bool
+	gz      *gzip.Writer
+}
+
+func (c *writer) WriteHeader(status int) {
+	// Informational responses like 103 Early Hints are sent right away
+	if status >= 100 && status < 200 {
+		c.w.WriteHeader(status)
+		return
+	}
+	if c.status != 0 {
+		return
+	}
+	c.status = status
+	if !c.compressible() {
+		c.start(false)
+	}
+}
+
+func (c *writer) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.started {
+		if c.gz != nil {
+			return c.gz.Write(p)
+		}
+		return c.w.Write(p)
+	}
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= c.option.MinSize {
+		if err := c.start(c.accepts && c.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush starts the response without waiting for the minimum size, so streamed
+// responses like server-sent events reach the client as they're written
+func (c *writer) Flush() {
+	if !c.started {
+		if c.status == 0 {
+			c.status = http.StatusOK
+		}
+		c.start(c.accepts && c.compressible())
+	}
+	if c.gz != nil {
+		c.gz.Flush()
+	}
+}
+
+// Close writes out the rest of the response
+func (c *writer) Close() error {
+	if !c.started {
+		// Let net/http write the default response if the handler wrote nothing
+		if c.status == 0 {
+			return nil
+		}
+		// Responses without a body, like redirects, don't vary by encoding
+		if len(c.buf) == 0 {
+			c.started = true
+			c.w.WriteHeader(c.status)
+			return nil
+		}
+		// The body is smaller than the minimum size
+		if err := c.start(false); err != nil {
+			return err
+		}
+	}
+	if c.gz == nil {
+		return nil
+	}
+	err := c.gz.Close()
+	c.gz.Reset(io.Discard)
+	c.pool.Put(c.gz)
+	c.gz = nil
+	return err
+}
+
+// start the response, either compressed or as-is, and write out the buffer
+func (c *writer) start(compress bool) error {
+	c.started = true
+	header := c.w.Header()
+	// Detect the content type before the body is compressed, otherwise net/http
+	// will detect the content type of the compressed body
+	if _, ok := header["Content-Type"]; !ok && len(c.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(c.buf))
+	}
+	if c.compressible() {
+		addVary(header, "Accept-Encoding")
+	}
+	// Without a content type, net/http would detect the content type of the
+	// compressed body
+	if compress && header.Get("Content-Type") != "" {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		// The compressed body isn't byte-for-byte the same as the original
+		if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			header.Set("ETag", "W/"+etag)
+		}
+		c.w.WriteHeader(c.status)
+		c.gz = c.pool.Get().(*gzip.Writer)
+		c.gz.Reset(c.w)
+		if len(c.buf) == 0 {
+			return nil
+		}
+		_, err := c.gz.Write(c.buf)
+		c.buf = nil
+		return err
+	}
+	c.w.WriteHeader(c.status)
+	if len(c.buf) == 0 {
+		return nil
+	}
+	_, err := c.w.Write(c.buf)
+	c.buf = nil
+	return err
+}
+
+// compressible returns true if the response could be compressed for clients
+// that accept it
+func (c *writer) compressible() bool {
+	if c.r.Method == http.MethodHead {
+		return false
+	}
+	switch c.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	header := c.w.Header()
+	// Already compressed, for example precompressed assets
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	if length := header.Get("Content-Length"); length != "" {
+		if n, err := strconv.Atoi(length); err == nil && n < c.option.MinSize {
+			return false
+		}
+	}
+	return !isCompressed(header.Get("Content-Type"))
+}
+
+// compressedTypes don't get any smaller when they're compressed again
+var compressedTypes = map[string]bool{
+	"application/gzip":             true,
+	"application/octet-stream":     true,
+	"application/pdf":              true,
+	"application/vnd.rar":          true,
+	"application/x-7z-compressed":  true,
+	"application/x-brotli":         true,
+	"application/x-bzip2":          true,
+	"application/x-gzip":           true,
+	"application/x-rar-compressed": true,
+	"application/x-xz":             true,
+	"application/zip":              true,
+	"application/zstd":             true,
+	"font/woff":                    true,
+	"font/woff2":                   true,
+}
+
+// isCompressed returns true for content types that are already compressed
+func isCompressed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		// SVGs and bitmaps are uncompressed
+		return mediaType != "image/svg+xml" && mediaType != "image/bmp" && mediaType != "image/x-icon"
+	case strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "audio/"):
+		return true
+	default:
+		return compressedTypes[mediaType]
+	}
+}
+
+// acceptsGzip parses the Accept-Encoding header to see if the client accepts
+// gzip, respecting quality values like "gzip;q=0"
+func acceptsGzip(acceptEncoding string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(key, "q") {
+				continue
+			}
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				q = n
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+// addVary adds the value to the Vary header if it's not already there
+func addVary(header http.Header, value string) {
+	for _, vary := range header.Values("Vary") {
+		for _, v := range strings.Split(vary, ",") {
+			v = strings.TrimSpace(v)
+			if v == "*" || strings.EqualFold(v, value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
@@ -0,0 +1,229 @@
+package compress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/middleware/compress"
+)
+
+var large = strings.Repeat("<p>hello world</p>", 100)
+
+func serve(h http.Handler, method, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func gunzip(t testing.TB, body *bytes.Buffer) string {
+	t.Helper()
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCompress(t *testing.T) {
+	is := is.New(t)
+	h := compress.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1800")
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte(large))
+	}))
+	rec := serve(h, http.MethodGet, "gzip, deflate, br")
+	is.Equal(200, rec.Code)
+	is.Equal("gzip", rec.Header().Get("Content-Encoding"))
+	is.Equal("Accept-Encoding", rec.Header().Get("Vary"))
+	is.Equal("text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	is.Equal("", rec.Header().Get("Content-Length"))
+	is.Equal(`W/"abc"`, rec.Header().Get("ETag"))
+	is.True(rec.Body.Len() < len(large))
+	is.Equal(large, gunzip(t, rec.Body))
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	is := is.New(t)
+	h := compress.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(large))
+	}))
+	tests := []struct {
+		accept   string
+		encoding string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"GZIP;q=0.5", "gzip"},
+		{"*", "gzip"},
+		{"gzip;q=0", ""},
+		{"gzip;q=0, *", ""},
+		{"br, *;q=0", ""},
+		{"identity", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, test.accept)
+			is.Equal(test.encoding, rec.Header().Get("Content-Encoding"))
+			// The response depends on Accept-Encoding either way
+			is.Equal("Accept-Encoding", rec.Header().Get("Vary"))
+			if test.encoding == "" {
+				is.Equal(large, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	is := is.New(t)
+	tests := []struct {
+		name     string
+		method   string
+		handler  http.HandlerFunc
+		encoding string
+		vary     string
+	}{
+		{
+			name: "small body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("hello"))
+			},
+			vary: "Accept-Encoding",
+		},
+		{
+			name: "small content length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", "5")
+				w.Write([]byte("hello"))
+			},
+		},
+		{
+			name: "image",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				w.Write([]byte(large))
+			},
+		},
+		{
+			name: "precompressed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/javascript")
+				w.Header().Set("Content-Encoding", "br")
+				w.Write([]byte(large))
+			},
+			encoding: "br",
+		},
+		{
+			name:   "head",
+			method: http.MethodHead,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(large))
+			},
+		},
+		{
+			name: "partial content",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Range", "bytes 0-1799/2000")
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(large))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := serve(compress.New()(test.handler), method, "gzip")
+			is.Equal(test.encoding, rec.Header().Get("Content-Encoding"))
+			is.Equal(test.vary, rec.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	is := is.New(t)
+	h := compress.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.Copy(w, strings.NewReader(large))
+	}))
+	rec := serve(h, http.MethodGet, "gzip")
+	is.Equal(404, rec.Code)
+	is.Equal("gzip", rec.Header().Get("Content-Encoding"))
+	is.Equal(large, gunzip(t, rec.Body))
+	// Status without a body
+	h = compress.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	rec = serve(h, http.MethodGet, "gzip")
+	is.Equal(201, rec.Code)
+	is.Equal("", rec.Header().Get("Content-Encoding"))
+	is.Equal("", rec.Header().Get("Vary"))
+	is.Equal(0, rec.Body.Len())
+}
+
+func TestMinSize(t *testing.T) {
+	is := is.New(t)
+	h := compress.New(compress.WithMinSize(0))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	rec := serve(h, http.MethodGet, "gzip")
+	is.Equal("gzip", rec.Header().Get("Content-Encoding"))
+	is.Equal("hello", gunzip(t, rec.Body))
+}
+
+func TestFlush(t *testing.T) {
+	is := is.New(t)
+	flushed := make(chan string)
+	h := compress.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		is.True(ok)
+		for _, event := range []string{"a", "b"} {
+			w.Write([]byte("data: " + event + "\n\n"))
+			flusher.Flush()
+			flushed <- event
+		}
+	}))
+	server := httptest.NewServer(h)
+	defer server.Close()
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	is.NoErr(err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	done := make(chan *http.Response)
+	go func() {
+		res, err := http.DefaultTransport.RoundTrip(req)
+		is.NoErr(err)
+		done <- res
+	}()
+	is.Equal("a", <-flushed)
+	res := <-done
+	defer res.Body.Close()
+	is.Equal("gzip", res.Header.Get("Content-Encoding"))
+	gz, err := gzip.NewReader(res.Body)
+	is.NoErr(err)
+	// The first event arrives before the handler finishes
+	buf := make([]byte, len("data: a\n\n"))
+	_, err = io.ReadFull(gz, buf)
+	is.NoErr(err)
+	is.Equal("data: a\n\n", string(buf))
+	is.Equal("b", <-flushed)
+	rest, err := io.ReadAll(gz)
+	is.NoErr(err)
+	is.Equal("data: b\n\n", string(rest))
+}
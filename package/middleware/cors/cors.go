@@ -0,0 +1,250 @@
+package cors
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/livebud/bud/package/middleware"
+)
+
+// Option to configure CORS
+type Option = func(o *option)
+
+type option struct {
+	Origins        []string
+	Methods        []string
+	Headers        []string
+	ExposedHeaders []string
+	Credentials    bool
+	MaxAge         time.Duration
+}
+
+// WithOrigins sets the origins that may make requests, like
+// "https://example.com". Origins may contain "*" to match any subdomain or
+// port, like "https://*.example.com" or "http://localhost:*". Defaults to "*",
+// which allows every origin.
+func WithOrigins(origins ...string) Option {
+	return func(o *option) {
+		o.Origins = origins
+	}
+}
+
+// WithMethods sets the methods that preflight requests allow. Defaults to GET,
+// HEAD, POST, PUT, PATCH and DELETE.
+func WithMethods(methods ...string) Option {
+	return func(o *option) {
+		o.Methods = methods
+	}
+}
+
+// WithHeaders sets the request headers that preflight requests allow. By
+// default, any headers the request asks for are allowed.
+func WithHeaders(headers ...string) Option {
+	return func(o *option) {
+		o.Headers = headers
+	}
+}
+
+// WithExposedHeaders sets the response headers that scripts can read, beyond
+// the headers that are always exposed like Content-Type.
+func WithExposedHeaders(headers ...string) Option {
+	return func(o *option) {
+		o.ExposedHeaders = headers
+	}
+}
+
+// WithCredentials allows requests with cookies. Credentials are only allowed
+// for origins that are listed, not for origins allowed by "*".
+func WithCredentials(credentials bool) Option {
+	return func(o *option) {
+		o.Credentials = credentials
+	}
+}
+
+// WithMaxAge sets how long browsers may cache the response to a preflight
+// request
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(o *option) {
+		o.MaxAge = maxAge
+	}
+}
+
+// New CORS middleware that lets pages on other origins call your routes.
+// Preflight requests are answered by the middleware directly. Add the
+// middleware to a router group to only allow cross-origin requests to the
+// routes within the group.
+func New(options ...Option) middleware.Middleware {
+	opt := &option{
+		Origins: []string{"*"},
+		Methods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+	}
+	for _, option := range options {
+		option(opt)
+	}
+	p := newPolicy(opt)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				p.preflight(w, r)
+				return
+			}
+			p.actual(w, r)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// policy is the compiled set of options
+type policy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	patterns    []*regexp.Regexp
+	methods     map[string]bool
+	headers     map[string]bool // nil allows any header
+	allowMethod string
+	allowHeader string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+func newPolicy(opt *option) *policy {
+	p := &policy{
+		origins:     map[string]bool{},
+		methods:     map[string]bool{},
+		allowMethod: strings.Join(opt.Methods, ", "),
+		allowHeader: strings.Join(opt.Headers, ", "),
+		exposed:     strings.Join(opt.ExposedHeaders, ", "),
+		credentials: opt.Credentials,
+	}
+	for _, origin := range opt.Origins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			p.patterns = append(p.patterns, compilePattern(origin))
+		default:
+			p.origins[origin] = true
+		}
+	}
+	for _, method := range opt.Methods {
+		p.methods[strings.ToUpper(method)] = true
+	}
+	if opt.Headers != nil {
+		p.headers = map[string]bool{}
+		for _, header := range opt.Headers {
+			p.headers[http.CanonicalHeaderKey(header)] = true
+		}
+	}
+	if seconds := int(opt.MaxAge.Seconds()); seconds > 0 {
+		p.maxAge = strconv.Itoa(seconds)
+	}
+	return p
+}
+
+// compilePattern turns an origin pattern into a regular expression where "*"
+// matches part of a host or port
+func compilePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile(`^` + strings.Join(parts, `[a-z0-9.-]+`) + `$`)
+}
+
+// match the origin, returning the value of the Access-Control-Allow-Origin
+// header and whether credentials are allowed
+func (p *policy) match(origin string) (allowOrigin string, credentials bool) {
+	lower := strings.ToLower(origin)
+	if p.origins[lower] {
+		return origin, p.credentials
+	}
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(lower) {
+			return origin, p.credentials
+		}
+	}
+	if p.anyOrigin {
+		return "*", false
+	}
+	return "", false
+}
+
+// vary adds Origin to the Vary header when the response depends on it
+func (p *policy) vary(header http.Header, values ...string) {
+	if !p.anyOrigin || len(p.origins) > 0 || len(p.patterns) > 0 {
+		values = append([]string{"Origin"}, values...)
+	}
+	for _, value := range values {
+		header.Add("Vary", value)
+	}
+}
+
+// preflight responds to a preflight request. The response doesn't include any
+// CORS headers when the request isn't allowed, so the browser blocks it.
+func (p *policy) preflight(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	p.vary(header, "Access-Control-Request-Method", "Access-Control-Request-Headers")
+	allowOrigin, credentials := p.match(r.Header.Get("Origin"))
+	if allowOrigin == "" || !p.methods[r.Header.Get("Access-Control-Request-Method")] {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	requested := r.Header.Get("Access-Control-Request-Headers")
+	allowHeader := p.allowHeader
+	if p.headers == nil {
+		allowHeader = requested
+	} else {
+		for _, name := range strings.Split(requested, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && !p.headers[http.CanonicalHeaderKey(name)] {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+	}
+	header.Set("Access-Control-Allow-Origin", allowOrigin)
+	header.Set("Access-Control-Allow-Methods", p.allowMethod)
+	if allowHeader != "" {
+		header.Set("Access-Control-Allow-Headers", allowHeader)
+	}
+	if credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if p.maxAge != "" {
+		header.Set("Access-Control-Max-Age", p.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// actual adds the CORS headers to an actual request from an allowed origin
+func (p *policy) actual(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	p.vary(header)
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	allowOrigin, credentials := p.match(origin)
+	if allowOrigin == "" {
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", allowOrigin)
+	if credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if p.exposed != "" {
+		header.Set("Access-Control-Expose-Headers", p.exposed)
+	}
+}
@@ -0,0 +1,173 @@
+package cors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/middleware/cors"
+	"github.com/livebud/bud/package/router"
+)
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+})
+
+func serve(h http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func preflight(origin, method, headers string) map[string]string {
+	return map[string]string{
+		"Origin":                         origin,
+		"Access-Control-Request-Method":  method,
+		"Access-Control-Request-Headers": headers,
+	}
+}
+
+func TestAnyOrigin(t *testing.T) {
+	is := is.New(t)
+	h := cors.New()(ok)
+	rec := serve(h, http.MethodGet, "/", map[string]string{"Origin": "https://spa.com"})
+	is.Equal(200, rec.Code)
+	is.Equal("ok", rec.Body.String())
+	is.Equal("*", rec.Header().Get("Access-Control-Allow-Origin"))
+	is.Equal("", rec.Header().Get("Access-Control-Allow-Credentials"))
+	is.Equal("", rec.Header().Get("Vary"))
+	// Preflight
+	rec = serve(h, http.MethodOptions, "/", preflight("https://spa.com", "PUT", "Content-Type, X-CSRF-Token"))
+	is.Equal(204, rec.Code)
+	is.Equal("", rec.Body.String())
+	is.Equal("*", rec.Header().Get("Access-Control-Allow-Origin"))
+	is.Equal("GET, HEAD, POST, PUT, PATCH, DELETE", rec.Header().Get("Access-Control-Allow-Methods"))
+	is.Equal("Content-Type, X-CSRF-Token", rec.Header().Get("Access-Control-Allow-Headers"))
+	is.Equal("", rec.Header().Get("Access-Control-Max-Age"))
+	// Requests without an Origin aren't CORS requests
+	rec = serve(h, http.MethodGet, "/", nil)
+	is.Equal("ok", rec.Body.String())
+	is.Equal("", rec.Header().Get("Access-Control-Allow-Origin"))
+	// OPTIONS requests that aren't preflights are passed through
+	rec = serve(h, http.MethodOptions, "/", map[string]string{"Origin": "https://spa.com"})
+	is.Equal("ok", rec.Body.String())
+}
+
+func TestOrigins(t *testing.T) {
+	is := is.New(t)
+	h := cors.New(
+		cors.WithOrigins("https://spa.com", "https://*.example.com", "http://localhost:*"),
+		cors.WithCredentials(true),
+		cors.WithExposedHeaders("X-Total-Count"),
+	)(ok)
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://spa.com", true},
+		{"https://SPA.com", true},
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"http://localhost:3000", true},
+		{"http://spa.com", false},
+		{"https://example.com", false},
+		{"https://evil.com/.example.com", false},
+		{"https://app.example.com.evil.com", false},
+		{"null", false},
+	}
+	for _, test := range tests {
+		t.Run(test.origin, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, "/", map[string]string{"Origin": test.origin})
+			// The request is still served, but the browser won't share the response
+			is.Equal("ok", rec.Body.String())
+			is.Equal("Origin", rec.Header().Get("Vary"))
+			if !test.allowed {
+				is.Equal("", rec.Header().Get("Access-Control-Allow-Origin"))
+				is.Equal("", rec.Header().Get("Access-Control-Allow-Credentials"))
+				return
+			}
+			is.Equal(test.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+			is.Equal("true", rec.Header().Get("Access-Control-Allow-Credentials"))
+			is.Equal("X-Total-Count", rec.Header().Get("Access-Control-Expose-Headers"))
+		})
+	}
+}
+
+func TestCredentialsNeedListedOrigin(t *testing.T) {
+	is := is.New(t)
+	h := cors.New(cors.WithOrigins("*", "https://spa.com"), cors.WithCredentials(true))(ok)
+	rec := serve(h, http.MethodGet, "/", map[string]string{"Origin": "https://spa.com"})
+	is.Equal("https://spa.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	is.Equal("true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	rec = serve(h, http.MethodGet, "/", map[string]string{"Origin": "https://other.com"})
+	is.Equal("*", rec.Header().Get("Access-Control-Allow-Origin"))
+	is.Equal("", rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestPreflight(t *testing.T) {
+	is := is.New(t)
+	h := cors.New(
+		cors.WithOrigins("https://spa.com"),
+		cors.WithMethods("GET", "POST"),
+		cors.WithHeaders("Content-Type", "X-CSRF-Token"),
+		cors.WithMaxAge(10*time.Minute),
+	)(ok)
+	rec := serve(h, http.MethodOptions, "/", preflight("https://spa.com", "POST", "content-type,x-csrf-token"))
+	is.Equal(204, rec.Code)
+	is.Equal("https://spa.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	is.Equal("GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	is.Equal("Content-Type, X-CSRF-Token", rec.Header().Get("Access-Control-Allow-Headers"))
+	is.Equal("600", rec.Header().Get("Access-Control-Max-Age"))
+	is.Equal([]string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, rec.Header().Values("Vary"))
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{"other origin", preflight("https://evil.com", "POST", "")},
+		{"method not allowed", preflight("https://spa.com", "DELETE", "")},
+		{"header not allowed", preflight("https://spa.com", "POST", "Content-Type, X-Secret")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := serve(h, http.MethodOptions, "/", test.headers)
+			is.Equal(204, rec.Code)
+			is.Equal("", rec.Body.String())
+			is.Equal("", rec.Header().Get("Access-Control-Allow-Origin"))
+			is.Equal("", rec.Header().Get("Access-Control-Allow-Methods"))
+		})
+	}
+}
+
+func TestRouterGroup(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	api := rt.Group("/api", cors.New(cors.WithOrigins("https://spa.com")))
+	is.NoErr(api.Post("/posts", ok))
+	is.NoErr(api.Get("/posts/:id", ok))
+	is.NoErr(rt.Post("/login", ok))
+	// Preflights succeed for routes that only have other methods
+	rec := serve(rt, http.MethodOptions, "/api/posts", preflight("https://spa.com", "POST", "Content-Type"))
+	is.Equal(204, rec.Code)
+	is.Equal("https://spa.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	is.Equal("POST, OPTIONS", rec.Header().Get("Allow"))
+	rec = serve(rt, http.MethodOptions, "/api/posts/10", preflight("https://spa.com", "GET", ""))
+	is.Equal(204, rec.Code)
+	is.Equal("https://spa.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	rec = serve(rt, http.MethodPost, "/api/posts", map[string]string{"Origin": "https://spa.com"})
+	is.Equal(200, rec.Code)
+	is.Equal("https://spa.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	// Routes outside of the group don't allow other origins
+	rec = serve(rt, http.MethodOptions, "/login", preflight("https://spa.com", "POST", "Content-Type"))
+	is.Equal(204, rec.Code)
+	is.Equal("POST, OPTIONS", rec.Header().Get("Allow"))
+	is.Equal("", rec.Header().Get("Access-Control-Allow-Origin"))
+	// Unknown routes are still not found
+	rec = serve(rt, http.MethodOptions, "/api/users", preflight("https://spa.com", "POST", ""))
+	is.Equal(404, rec.Code)
+}
@@ -0,0 +1,215 @@
+package csrf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/livebud/bud/package/middleware"
+	"github.com/livebud/bud/package/session"
+)
+
+const (
+	// Field is the name of the form field that holds the token
+	Field = "_csrf"
+	// Header is the name of the header that holds the token
+	Header = "X-CSRF-Token"
+	// sessionKey is where the token is kept in the session
+	sessionKey = "_csrf"
+	// tokenSize is the number of random bytes in a token
+	tokenSize = 32
+)
+
+// ErrInvalidToken occurs when an unsafe request is missing a valid token
+var ErrInvalidToken = errors.New("csrf: invalid token")
+
+// New CSRF middleware that protects unsafe requests (POST, PUT, PATCH and
+// DELETE) from being forged by other sites. Requests must either come from the
+// same origin, be JSON requests or include the session's token in the "_csrf"
+// form field or the "X-CSRF-Token" header. The middleware needs to run within
+// the session middleware.
+func New() middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isSafe(r.Method) || isJSON(r) || isSameOrigin(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := verify(r); err != nil {
+				http.Error(w, "Forbidden - "+err.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Token returns a token for the request's session, creating one if the session
+// doesn't have one yet. Each token is masked differently, so the token can't
+// be guessed from compressed responses.
+func Token(r *http.Request) (string, error) {
+	sess, err := session.Load(r)
+	if err != nil {
+		return "", err
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(sess.Get(sessionKey))
+	if err != nil || len(secret) != tokenSize {
+		secret = make([]byte, tokenSize)
+		if _, err := rand.Read(secret); err != nil {
+			return "", err
+		}
+		sess.Set(sessionKey, base64.RawURLEncoding.EncodeToString(secret))
+	}
+	return mask(secret)
+}
+
+// verify the token in the request against the session's token
+func verify(r *http.Request) error {
+	sess, err := session.Load(r)
+	if err != nil {
+		return err
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(sess.Get(sessionKey))
+	if err != nil || len(secret) != tokenSize {
+		return ErrInvalidToken
+	}
+	token := r.Header.Get(Header)
+	if token == "" {
+		switch mediaType(r) {
+		case "application/x-www-form-urlencoded":
+			token = r.PostFormValue(Field)
+		case "multipart/form-data":
+			token = multipartToken(r)
+		}
+	}
+	unmasked, ok := unmask(token)
+	if !ok || subtle.ConstantTimeCompare(unmasked, secret) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// mask the secret with a random one-time pad
+func mask(secret []byte) (string, error) {
+	token := make([]byte, tokenSize*2)
+	pad := token[:tokenSize]
+	if _, err := rand.Read(pad); err != nil {
+		return "", err
+	}
+	for i := 0; i < tokenSize; i++ {
+		token[tokenSize+i] = pad[i] ^ secret[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// unmask the token back into the secret
+func unmask(token string) ([]byte, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) != tokenSize*2 {
+		return nil, false
+	}
+	secret := make([]byte, tokenSize)
+	for i := 0; i < tokenSize; i++ {
+		secret[i] = data[i] ^ data[tokenSize+i]
+	}
+	return secret, true
+}
+
+// isSafe returns true for methods that shouldn't change anything
+func isSafe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// isJSON returns true for JSON requests. Browsers can't send these to other
+// origins without a CORS preflight.
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// mediaType returns the media type of the request body
+func mediaType(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// maxPeek is the number of bytes of a multipart body that are read to find the
+// token
+const maxPeek = 32 << 10
+
+// multipartToken reads the token from the fields before the first file in a
+// multipart body. Multipart bodies aren't parsed here so they keep the upload
+// limits of the action, instead the bytes that are read are put back into the
+// body and files are never read.
+func multipartToken(r *http.Request) string {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		return ""
+	}
+	body := r.Body
+	peeked := new(bytes.Buffer)
+	defer func() {
+		r.Body = &replayBody{io.MultiReader(peeked, body), body}
+	}()
+	reader := multipart.NewReader(io.TeeReader(io.LimitReader(body, maxPeek), peeked), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil || part.FileName() != "" {
+			return ""
+		}
+		value, err := io.ReadAll(io.LimitReader(part, 1024))
+		if err != nil {
+			return ""
+		}
+		if part.FormName() == Field {
+			return string(value)
+		}
+	}
+}
+
+// replayBody reads the peeked bytes before the rest of the body
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// isSameOrigin uses the headers that browsers add to check whether the request
+// came from the same origin
+func isSameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	case "":
+		// Older browsers don't send Sec-Fetch-Site, so check the Origin
+	default:
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" || origin == "null" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
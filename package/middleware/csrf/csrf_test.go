@@ -0,0 +1,245 @@
+package csrf_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/middleware"
+	"github.com/livebud/bud/package/middleware/csrf"
+	"github.com/livebud/bud/package/middleware/methodoverride"
+	"github.com/livebud/bud/package/session"
+)
+
+// server renders the token on GET requests and responds with "ok" otherwise
+func server() http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			token, err := csrf.Token(r)
+			if err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+			w.Write([]byte(token))
+			return
+		}
+		w.Write([]byte("ok " + r.Method))
+	})
+	stack := middleware.Compose(
+		methodoverride.New(),
+		session.New(log.Discard, session.NewMemoryStore()).Middleware,
+		csrf.New(),
+	)
+	return stack(handler)
+}
+
+// visit the form to get a session cookie and a token
+func visit(t testing.TB, h http.Handler) (*http.Cookie, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/form", nil))
+	res := rec.Result()
+	if res.StatusCode != 200 {
+		t.Fatalf("unexpected status %d", res.StatusCode)
+	}
+	cookies := res.Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a session cookie, got %d cookies", len(cookies))
+	}
+	return cookies[0], rec.Body.String()
+}
+
+func post(h http.Handler, cookie *http.Cookie, contentType, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(body))
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+const formType = "application/x-www-form-urlencoded"
+
+func TestFormToken(t *testing.T) {
+	is := is.New(t)
+	h := server()
+	cookie, token := visit(t, h)
+	rec := post(h, cookie, formType, url.Values{"_csrf": {token}}.Encode(), nil)
+	is.Equal(200, rec.Code)
+	is.Equal("ok POST", rec.Body.String())
+	// Works with method overrides
+	rec = post(h, cookie, formType, url.Values{"_csrf": {token}, "_method": {"DELETE"}}.Encode(), nil)
+	is.Equal(200, rec.Code)
+	is.Equal("ok DELETE", rec.Body.String())
+}
+
+func TestTokensAreMasked(t *testing.T) {
+	is := is.New(t)
+	h := server()
+	cookie, token := visit(t, h)
+	// Render the form again within the same session
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	token2 := rec.Body.String()
+	is.True(token != token2)
+	// Both tokens are valid
+	rec = post(h, cookie, formType, url.Values{"_csrf": {token}}.Encode(), nil)
+	is.Equal(200, rec.Code)
+	rec = post(h, cookie, formType, url.Values{"_csrf": {token2}}.Encode(), nil)
+	is.Equal(200, rec.Code)
+}
+
+func TestHeaderToken(t *testing.T) {
+	is := is.New(t)
+	h := server()
+	cookie, token := visit(t, h)
+	rec := post(h, cookie, "text/plain", "hi", map[string]string{"X-CSRF-Token": token})
+	is.Equal(200, rec.Code)
+}
+
+func TestInvalidToken(t *testing.T) {
+	is := is.New(t)
+	h := server()
+	cookie, token := visit(t, h)
+	_, other := visit(t, h)
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		body   string
+	}{
+		{"missing token", cookie, ""},
+		{"no session", nil, url.Values{"_csrf": {token}}.Encode()},
+		{"another session's token", cookie, url.Values{"_csrf": {other}}.Encode()},
+		{"garbage token", cookie, url.Values{"_csrf": {"abc"}}.Encode()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := post(h, test.cookie, formType, test.body, nil)
+			is.Equal(403, rec.Code)
+			is.Equal("Forbidden - csrf: invalid token\n", rec.Body.String())
+		})
+	}
+}
+
+func TestMultipartToken(t *testing.T) {
+	is := is.New(t)
+	h := server()
+	cookie, token := visit(t, h)
+	const multipartType = "multipart/form-data; boundary=x"
+	body := "--x\r\nContent-Disposition: form-data; name=\"_csrf\"\r\n\r\n" + token + "\r\n--x--\r\n"
+	rec := post(h, cookie, multipartType, body, nil)
+	is.Equal(200, rec.Code)
+	is.Equal("ok POST", rec.Body.String())
+	// Fields before the token are skipped
+	body = "--x\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nhello\r\n" +
+		"--x\r\nContent-Disposition: form-data; name=\"_csrf\"\r\n\r\n" + token + "\r\n--x--\r\n"
+	rec = post(h, cookie, multipartType, body, nil)
+	is.Equal(200, rec.Code)
+	// Tokens after a file aren't read, so files aren't buffered
+	body = "--x\r\nContent-Disposition: form-data; name=\"avatar\"; filename=\"a.png\"\r\n\r\npng\r\n" +
+		"--x\r\nContent-Disposition: form-data; name=\"_csrf\"\r\n\r\n" + token + "\r\n--x--\r\n"
+	rec = post(h, cookie, multipartType, body, nil)
+	is.Equal(403, rec.Code)
+	rec = post(h, cookie, multipartType, body, map[string]string{"X-CSRF-Token": token})
+	is.Equal(200, rec.Code)
+}
+
+func TestMultipartBodyIsKept(t *testing.T) {
+	is := is.New(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			token, err := csrf.Token(r)
+			if err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+			w.Write([]byte(token))
+			return
+		}
+		file, _, err := r.FormFile("avatar")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Write([]byte(r.FormValue("title") + " " + string(data)))
+	})
+	stack := middleware.Compose(
+		session.New(log.Discard, session.NewMemoryStore()).Middleware,
+		csrf.New(),
+	)
+	h := stack(handler)
+	cookie, token := visit(t, h)
+	body := "--x\r\nContent-Disposition: form-data; name=\"_csrf\"\r\n\r\n" + token + "\r\n" +
+		"--x\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nhello\r\n" +
+		"--x\r\nContent-Disposition: form-data; name=\"avatar\"; filename=\"a.png\"\r\n\r\n" + strings.Repeat("png", 20000) + "\r\n--x--\r\n"
+	rec := post(h, cookie, "multipart/form-data; boundary=x", body, nil)
+	is.Equal(200, rec.Code)
+	is.Equal("hello "+strings.Repeat("png", 20000), rec.Body.String())
+}
+
+func TestExempt(t *testing.T) {
+	is := is.New(t)
+	h := server()
+	tests := []struct {
+		name        string
+		contentType string
+		headers     map[string]string
+		status      int
+	}{
+		{"json", "application/json", nil, 200},
+		{"json with charset", "application/json; charset=utf-8", nil, 200},
+		{"problem json", "application/problem+json", nil, 200},
+		{"same origin", formType, map[string]string{"Sec-Fetch-Site": "same-origin"}, 200},
+		{"typed in", formType, map[string]string{"Sec-Fetch-Site": "none"}, 200},
+		{"cross site", formType, map[string]string{"Sec-Fetch-Site": "cross-site"}, 403},
+		{"same site", formType, map[string]string{"Sec-Fetch-Site": "same-site"}, 403},
+		{"cross site with origin", formType, map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "http://example.com"}, 403},
+		{"matching origin", formType, map[string]string{"Origin": "http://example.com"}, 200},
+		{"other origin", formType, map[string]string{"Origin": "http://evil.com"}, 403},
+		{"null origin", formType, map[string]string{"Origin": "null"}, 403},
+		{"plain text", "text/plain", nil, 403},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := post(h, nil, test.contentType, "", test.headers)
+			is.Equal(test.status, rec.Code)
+		})
+	}
+}
+
+func TestSafeMethods(t *testing.T) {
+	is := is.New(t)
+	h := server()
+	for _, method := range []string{http.MethodHead, http.MethodOptions} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/form", nil))
+		is.Equal(200, rec.Code)
+	}
+}
+
+func TestNoSession(t *testing.T) {
+	is := is.New(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err := csrf.Token(req)
+	is.Equal(session.ErrNoMiddleware, err)
+}
@@ -0,0 +1,172 @@
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/livebud/bud/framework/controller/controllerrt/request"
+	"github.com/livebud/bud/framework/controller/controllerrt/response"
+	"github.com/livebud/bud/internal/stacktrace"
+	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/middleware"
+	"github.com/livebud/bud/package/router"
+)
+
+// ErrorRenderer renders the nearest error view for a route
+type ErrorRenderer interface {
+	ErrorRenderer(route string, status int, err error) http.Handler
+}
+
+// Option to configure recovery
+type Option = func(o *option)
+
+type option struct {
+	Renderer ErrorRenderer
+}
+
+// WithErrorRenderer renders HTML errors with the nearest error view of the
+// route that panicked. Without a renderer, HTML errors are plain text.
+func WithErrorRenderer(renderer ErrorRenderer) Option {
+	return func(o *option) {
+		o.Renderer = renderer
+	}
+}
+
+// errInternal is shown to the client when a handler panics. The panic itself
+// is only logged, since it may contain details that shouldn't be shared.
+var errInternal = errors.New("internal server error")
+
+// bodyHeaders describe the body the handler was writing before it panicked
+var bodyHeaders = []string{
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Length",
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+}
+
+// New recovery middleware that logs panics with their stack trace and responds
+// with a 500. Browsers get an HTML error page and other clients get a JSON
+// problem. If the response has already started, the connection is aborted
+// instead so the client doesn't mistake the partial response for a complete
+// one.
+func New(log log.Log, options ...Option) middleware.Middleware {
+	opt := &option{}
+	for _, option := range options {
+		option(opt)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = router.Track(r)
+			started := false
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				// Let the server abort the response without logging
+				if value == http.ErrAbortHandler {
+					panic(value)
+				}
+				log.Fields(map[string]interface{}{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"route":  router.Route(r),
+				}).Errorf("web: panic: %v\n%s", value, stack())
+				if started {
+					panic(http.ErrAbortHandler)
+				}
+				opt.render(w, r)
+			}()
+			next.ServeHTTP(httpsnoop.Wrap(w, httpsnoop.Hooks{
+				WriteHeader: func(writeHeader httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+					return func(code int) {
+						// Informational responses don't start the final response
+						if code >= 200 {
+							started = true
+						}
+						writeHeader(code)
+					}
+				},
+				Write: func(write httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+					return func(p []byte) (int, error) {
+						started = true
+						return write(p)
+					}
+				},
+				ReadFrom: func(readFrom httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+					return func(src io.Reader) (int64, error) {
+						started = true
+						return readFrom(src)
+					}
+				},
+				Flush: func(flush httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+					return func() {
+						started = true
+						flush()
+					}
+				},
+			}), r)
+		})
+	}
+}
+
+// render the error in the format the client accepts
+func (o *option) render(w http.ResponseWriter, r *http.Request) {
+	// Clear out the headers of the response the handler was writing
+	header := w.Header()
+	for _, key := range bodyHeaders {
+		header.Del(key)
+	}
+	if !request.Accepts(r).Accepts("text/html") {
+		response.Problem(errInternal).ServeHTTP(w, r)
+		return
+	}
+	if o.Renderer == nil {
+		response.ErrorHTML(errInternal).ServeHTTP(w, r)
+		return
+	}
+	o.Renderer.ErrorRenderer(router.Route(r), http.StatusInternalServerError, errInternal).ServeHTTP(w, r)
+}
+
+// recoveryPrefix is the prefix of the functions within this middleware
+var recoveryPrefix = reflect.TypeOf(option{}).PkgPath() + ".New."
+
+// stack formats the stack of the panicking goroutine, starting from the
+// function that panicked
+func stack() string {
+	frames := stacktrace.Frames(0)
+	// Skip past the runtime's panic frames
+	for i, frame := range frames {
+		if frame.Name() == "runtime.gopanic" {
+			frames = frames[i+1:]
+			break
+		}
+	}
+	// Skip runtime frames for panics like nil pointer dereferences
+	for len(frames) > 0 && strings.HasPrefix(frames[0].Name(), "runtime.") {
+		frames = frames[1:]
+	}
+	// Stop at this middleware, since the frames above it are the same for
+	// every request
+	for i, frame := range frames {
+		if strings.HasPrefix(frame.Name(), recoveryPrefix) {
+			frames = frames[:i]
+			break
+		}
+	}
+	str := new(strings.Builder)
+	for i, frame := range frames {
+		if i > 0 {
+			str.WriteString("\n")
+		}
+		str.WriteString(fmt.Sprintf("  %s\n    %s:%d", frame.Name(), frame.File(), frame.Line()))
+	}
+	return str.String()
+}
@@ -0,0 +1,100 @@
+package recovery_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/log/memory"
+	"github.com/livebud/bud/package/middleware/recovery"
+	"github.com/livebud/bud/package/router"
+)
+
+// renderer renders the error view with the route it was given
+type renderer struct{}
+
+func (renderer) ErrorRenderer(route string, status int, err error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "<h1>%s</h1><p>%s</p>", err, route)
+	})
+}
+
+func panicky() http.Handler {
+	rt := router.New()
+	rt.Get("/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "1")
+		panic("database password is hunter2")
+	}))
+	rt.Get("/stream", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		panic("oh no")
+	}))
+	return rt
+}
+
+func serve(h http.Handler, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRecoverHTML(t *testing.T) {
+	is := is.New(t)
+	handler := memory.New()
+	h := recovery.New(log.New(handler), recovery.WithErrorRenderer(renderer{}))(panicky())
+	rec := serve(h, "/users/10", "text/html")
+	is.Equal(500, rec.Code)
+	is.Equal("text/html", rec.Header().Get("Content-Type"))
+	// Headers from before the panic are kept
+	is.Equal("1", rec.Header().Get("X-Request-Id"))
+	// The error view is rendered for the route that panicked
+	is.Equal("<h1>internal server error</h1><p>/users/:id</p>", rec.Body.String())
+	// The panic is logged with its stack
+	is.Equal(1, len(handler.Entries))
+	entry := handler.Entries[0]
+	is.Equal(log.ErrorLevel, entry.Level)
+	is.Equal("/users/:id", entry.Fields.Get("route"))
+	is.In(entry.Message, "web: panic: database password is hunter2")
+	is.In(entry.Message, "recovery_test.go")
+	// The stack stops at the middleware
+	is.True(!strings.Contains(entry.Message, "recovery.go"))
+}
+
+func TestRecoverPlain(t *testing.T) {
+	is := is.New(t)
+	h := recovery.New(log.Discard)(panicky())
+	rec := serve(h, "/users/10", "text/html")
+	is.Equal(500, rec.Code)
+	is.Equal("text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	is.Equal("internal server error\n", rec.Body.String())
+}
+
+func TestRecoverJSON(t *testing.T) {
+	is := is.New(t)
+	h := recovery.New(log.Discard, recovery.WithErrorRenderer(renderer{}))(panicky())
+	rec := serve(h, "/users/10", "application/json")
+	is.Equal(500, rec.Code)
+	is.Equal("application/problem+json", rec.Header().Get("Content-Type"))
+	is.Equal(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal server error"}`, rec.Body.String())
+}
+
+func TestAbortStarted(t *testing.T) {
+	is := is.New(t)
+	handler := memory.New()
+	h := recovery.New(log.New(handler))(panicky())
+	defer func() {
+		is.Equal(http.ErrAbortHandler, recover())
+		is.Equal(1, len(handler.Entries))
+	}()
+	serve(h, "/stream", "text/html")
+	t.Fatal("expected the response to be aborted")
+}
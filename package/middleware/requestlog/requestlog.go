@@ -0,0 +1,36 @@
+package requestlog
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/middleware"
+	"github.com/livebud/bud/package/router"
+)
+
+// New request logging middleware. Each request is logged with its method,
+// path, the route that matched, the response status, the number of bytes
+// written and how long it took. Server errors are logged as errors.
+func New(log log.Log) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = router.Track(r)
+			metrics := httpsnoop.CaptureMetrics(next, w, r)
+			entry := log.Fields(map[string]interface{}{
+				"method":   r.Method,
+				"path":     r.URL.Path,
+				"route":    router.Route(r),
+				"status":   metrics.Code,
+				"bytes":    metrics.Written,
+				"duration": metrics.Duration.Round(time.Microsecond),
+			})
+			if metrics.Code >= 500 {
+				entry.Error("web: request")
+				return
+			}
+			entry.Info("web: request")
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package requestlog_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/log/memory"
+	"github.com/livebud/bud/package/middleware/requestlog"
+	"github.com/livebud/bud/package/router"
+)
+
+func TestLog(t *testing.T) {
+	is := is.New(t)
+	handler := memory.New()
+	rt := router.New()
+	is.NoErr(rt.Get("/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + router.Slots(r).Values().Get("id")))
+	})))
+	is.NoErr(rt.Post("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "oops", http.StatusInternalServerError)
+	})))
+	h := requestlog.New(log.New(handler))(rt)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/10", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts", nil))
+	is.Equal(3, len(handler.Entries))
+	entry := handler.Entries[0]
+	is.Equal(log.InfoLevel, entry.Level)
+	is.Equal("web: request", entry.Message)
+	is.Equal("GET", entry.Fields.Get("method"))
+	is.Equal("/users/10", entry.Fields.Get("path"))
+	is.Equal("/users/:id", entry.Fields.Get("route"))
+	is.Equal(200, entry.Fields.Get("status"))
+	is.Equal(int64(7), entry.Fields.Get("bytes"))
+	_, ok := entry.Fields.Get("duration").(time.Duration)
+	is.True(ok)
+	// Server errors are logged as errors
+	entry = handler.Entries[1]
+	is.Equal(log.ErrorLevel, entry.Level)
+	is.Equal("/users", entry.Fields.Get("route"))
+	is.Equal(500, entry.Fields.Get("status"))
+	// Unmatched requests don't have a route
+	entry = handler.Entries[2]
+	is.Equal(log.InfoLevel, entry.Level)
+	is.Equal("", entry.Fields.Get("route"))
+	is.Equal(404, entry.Fields.Get("status"))
+}
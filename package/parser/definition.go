@@ -17,6 +17,11 @@ func (pkg *Package) definition(name string) (decl Declaration, err error) {
 	var file *File
 	var ts *ast.TypeSpec
 	ast.Inspect(pkg.node, func(node ast.Node) bool {
+		// Returning false only skips the children, so stop once we've found it.
+		// Otherwise a later literal like interface{} would replace the match.
+		if decl != nil {
+			return false
+		}
 		switch n := node.(type) {
 		case *ast.File:
 			file = pkg.File(n.Name.Name)
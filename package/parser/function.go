@@ -32,6 +32,41 @@ func (fn *Function) Name() string {
 	return fn.node.Name.Name
 }
 
+// Directives returns the directive comments above the function with the
+// leading "//" trimmed (e.g. "bud:route POST /webhooks")
+func (fn *Function) Directives() (directives []string) {
+	if fn.node.Doc == nil {
+		return directives
+	}
+	for _, comment := range fn.node.Doc.List {
+		text := strings.TrimPrefix(comment.Text, "//")
+		// Directives don't have a space after the slashes
+		if text == comment.Text || !isDirective(text) {
+			continue
+		}
+		directives = append(directives, text)
+	}
+	return directives
+}
+
+// isDirective follows the Go convention of "//[a-z0-9]+:[a-z0-9]"
+func isDirective(text string) bool {
+	colon := strings.Index(text, ":")
+	if colon <= 0 || colon+1 >= len(text) {
+		return false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		c := text[i]
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
 // Receiver returns the receiver field, if any
 func (fn *Function) Receiver() *Receiver {
 	if fn.node.Recv == nil {
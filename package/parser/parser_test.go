@@ -2,6 +2,7 @@ package parser_test
 
 import (
 	"context"
+	"os"
 	"path"
 	"path/filepath"
 	"testing"
@@ -189,6 +190,40 @@ func TestNetHTTP(t *testing.T) {
 	is.Equal(stct.Name(), "Request")
 }
 
+func TestStructBeforeInterfaceLiteral(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module app.com\n"), 0644)
+	is.NoErr(err)
+	err = os.WriteFile(filepath.Join(dir, "app.go"), []byte(`
+		package app
+
+		type A struct {
+			B *B
+		}
+
+		type B struct {
+			name string
+		}
+
+		func (b *B) Send(v interface{}) error {
+			return nil
+		}
+	`), 0644)
+	is.NoErr(err)
+	module, err := gomod.Find(dir)
+	is.NoErr(err)
+	p := parser.New(module, module)
+	pkg, err := p.Parse(".")
+	is.NoErr(err)
+	field := pkg.Struct("A").Field("B")
+	is.True(field != nil)
+	def, err := field.Definition()
+	is.NoErr(err)
+	is.Equal(def.Name(), "B")
+	is.Equal(def.Kind(), parser.KindStruct)
+}
+
 func TestGenerate(t *testing.T) {
 	is := is.New(t)
 	ctx := context.Background()
@@ -300,3 +335,37 @@ func TestAliasLookupModule(t *testing.T) {
 	is.Equal(importPath, "github.com/livebud/transpiler")
 	is.Equal(pkg.Directory(), path.Join(module.ModCache(), "github.com/livebud/transpiler@"+dep.Version))
 }
+
+func TestFunctionDirectives(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	td, err := testdir.Load()
+	is.NoErr(err)
+	td.Files["app.go"] = `
+		package app
+
+		type A struct {}
+
+		// Hook is called by stripe
+		//bud:route POST /webhooks/stripe
+		//go:noinline
+		// not:a directive
+		func (a *A) Hook() {}
+
+		func (a *A) Plain() {}
+	`
+	err = td.Write(ctx)
+	is.NoErr(err)
+	module, err := gomod.Find(td.Directory())
+	is.NoErr(err)
+	p := parser.New(module, module)
+	pkg, err := p.Parse(".")
+	is.NoErr(err)
+	stct := pkg.Struct("A")
+	is.True(stct != nil)
+	directives := stct.Method("Hook").Directives()
+	is.Equal(len(directives), 2)
+	is.Equal(directives[0], "bud:route POST /webhooks/stripe")
+	is.Equal(directives[1], "go:noinline")
+	is.Equal(len(stct.Method("Plain").Directives()), 0)
+}
@@ -560,6 +560,16 @@ func (t *MapType) node() ast.Expr {
 	return t.n
 }
 
+// Key type of the map
+func (t *MapType) Key() Type {
+	return getType(t.f, t.n.Key)
+}
+
+// Value type of the map
+func (t *MapType) Value() Type {
+	return getType(t.f, t.n.Value)
+}
+
 // ChanType struct
 type ChanType struct {
 	f filer
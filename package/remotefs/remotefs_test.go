@@ -11,11 +11,15 @@ import (
 	"testing"
 	"testing/fstest"
 
+	"github.com/livebud/bud/internal/dag"
 	"github.com/livebud/bud/internal/is"
 	"github.com/livebud/bud/internal/testsub"
+	"github.com/livebud/bud/package/genfs"
+	"github.com/livebud/bud/package/log/testlog"
 	"github.com/livebud/bud/package/remotefs"
 	"github.com/livebud/bud/package/socket"
 	"github.com/livebud/bud/package/vfs"
+	"github.com/livebud/bud/package/virtual"
 )
 
 func listen(t testing.TB) (net.Listener, error) {
@@ -57,6 +61,29 @@ func TestReadDir(t *testing.T) {
 	is.Equal(len(des), 1)
 }
 
+func TestReadDirSkipsNotExist(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	server, err := listen(t)
+	is.NoErr(err)
+	defer server.Close()
+	client, err := remotefs.Dial(ctx, server.Addr().String())
+	is.NoErr(err)
+	fsys := genfs.New(dag.Discard, virtual.List{}, testlog.New())
+	fsys.GenerateFile("bud/a.txt", func(fsys genfs.FS, file *genfs.File) error {
+		file.Data = []byte("a")
+		return nil
+	})
+	fsys.GenerateFile("bud/b.txt", func(fsys genfs.FS, file *genfs.File) error {
+		return fs.ErrNotExist
+	})
+	go remotefs.Serve(fsys, server)
+	des, err := fs.ReadDir(client, "bud")
+	is.NoErr(err)
+	is.Equal(len(des), 1)
+	is.Equal(des[0].Name(), "a.txt")
+}
+
 func TestFS(t *testing.T) {
 	is := is.New(t)
 	ctx := context.Background()
@@ -1,6 +1,7 @@
 package remotefs
 
 import (
+	"errors"
 	"io"
 	"io/fs"
 
@@ -73,6 +74,10 @@ func (s *Service) ReadDir(name string, vdes *[]fs.DirEntry) error {
 	for _, de := range des {
 		stat, err := de.Info()
 		if err != nil {
+			// Skip generated files that turn out not to exist
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
 			return err
 		}
 		*vdes = append(*vdes, &virtual.DirEntry{
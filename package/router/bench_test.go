@@ -0,0 +1,112 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livebud/bud/package/router"
+	"github.com/livebud/bud/package/router/lex"
+)
+
+// noop handler that doesn't write anything
+var noop = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+// discard is a response writer that doesn't allocate
+type discard struct {
+	header http.Header
+}
+
+func (d *discard) Header() http.Header         { return d.header }
+func (d *discard) Write(p []byte) (int, error) { return len(p), nil }
+func (d *discard) WriteHeader(int)             {}
+
+// large builds a router with the RESTful routes of n resources, each with a
+// nested comments resource
+func large(tb testing.TB, n int) *router.Router {
+	tb.Helper()
+	rt := router.New()
+	for i := 0; i < n; i++ {
+		resource := fmt.Sprintf("/resource%d", i)
+		nested := resource + "/:resource_id/comments"
+		for _, prefix := range []string{resource, nested} {
+			routes := []struct {
+				method string
+				route  string
+			}{
+				{http.MethodGet, prefix},
+				{http.MethodGet, prefix + "/new"},
+				{http.MethodPost, prefix},
+				{http.MethodGet, prefix + "/:id.:format?"},
+				{http.MethodGet, prefix + "/:id/edit"},
+				{http.MethodPatch, prefix + "/:id.:format?"},
+				{http.MethodDelete, prefix + "/:id.:format?"},
+			}
+			for _, route := range routes {
+				if err := rt.Add(route.method, route.route, noop); err != nil {
+					tb.Fatal(err)
+				}
+			}
+		}
+	}
+	return rt
+}
+
+func benchmark(b *testing.B, n int, method, path string) {
+	rt := large(b, n)
+	req := httptest.NewRequest(method, path, nil)
+	w := &discard{http.Header{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rt.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkStatic(b *testing.B) {
+	benchmark(b, 10, http.MethodGet, "/resource5/new")
+}
+
+func BenchmarkStaticLarge(b *testing.B) {
+	benchmark(b, 500, http.MethodGet, "/resource250/new")
+}
+
+func BenchmarkSlot(b *testing.B) {
+	benchmark(b, 10, http.MethodGet, "/resource5/10/edit")
+}
+
+func BenchmarkSlotLarge(b *testing.B) {
+	benchmark(b, 500, http.MethodGet, "/resource250/10/edit")
+}
+
+func BenchmarkNestedLarge(b *testing.B) {
+	benchmark(b, 500, http.MethodPatch, "/resource250/10/comments/20.json")
+}
+
+func BenchmarkNotFoundLarge(b *testing.B) {
+	benchmark(b, 500, http.MethodGet, "/resource250/10/missing")
+}
+
+func BenchmarkLex(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lexer := lex.New("/resource/:resource_id/comments/:id.:format?")
+		for token := lexer.Next(); token.Type != lex.EndToken; token = lexer.Next() {
+		}
+	}
+}
+
+func TestStaticNoAllocs(t *testing.T) {
+	rt := large(t, 100)
+	w := &discard{http.Header{}}
+	for _, path := range []string{"/resource50", "/resource50/new", "/resource50/"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		allocs := testing.AllocsPerRun(100, func() {
+			rt.ServeHTTP(w, req)
+		})
+		if allocs != 0 {
+			t.Fatalf("expected no allocations for %s, got %v", path, allocs)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/livebud/bud/package/router/lex"
+	"github.com/livebud/bud/package/router/radix"
+)
+
+// Case determines how the router treats the case of paths
+type Case uint8
+
+const (
+	// Insensitive lowercases routes and matches paths regardless of their case.
+	// This is the default.
+	Insensitive Case = iota
+	// Sensitive keeps the case of routes and only matches paths with the same
+	// case, so /README.md and /readme.md are different routes.
+	Sensitive
+	// Redirect matches paths like Insensitive, but redirects GET and HEAD
+	// requests with uppercase letters in the route's path to the lowercase URL.
+	// Slot values keep their case.
+	Redirect
+)
+
+var cases = [...]string{
+	Insensitive: "insensitive",
+	Sensitive:   "sensitive",
+	Redirect:    "redirect",
+}
+
+func (c Case) String() string {
+	if int(c) < len(cases) {
+		return cases[c]
+	}
+	return fmt.Sprintf("Case(%d)", c)
+}
+
+// ParseCase parses the case from a string like "sensitive"
+func ParseCase(s string) (Case, error) {
+	for c, name := range cases {
+		if strings.EqualFold(s, name) {
+			return Case(c), nil
+		}
+	}
+	return Insensitive, fmt.Errorf("router: unknown case %q. Expected insensitive, sensitive or redirect", s)
+}
+
+// SetCase sets how the router treats the case of paths. The case must be set
+// before adding routes.
+func (rt *Router) SetCase(c Case) error {
+	if int(c) >= len(cases) {
+		return fmt.Errorf("router: unknown case %s", c)
+	}
+	if rt.hasRoutes() {
+		return fmt.Errorf("router: the case must be set before adding routes")
+	}
+	rt.mode = c
+	return nil
+}
+
+// hasRoutes returns true if any routes have been added
+func (rt *Router) hasRoutes() bool {
+	if len(rt.methods) > 0 {
+		return true
+	}
+	for _, host := range rt.hosts {
+		if len(host.methods) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tree creates a radix tree for the router's case
+func (rt *Router) tree() radix.Tree {
+	if rt.mode == Sensitive {
+		return radix.NewCaseSensitive()
+	}
+	return radix.New()
+}
+
+// normalize the route for the router's case
+func (rt *Router) normalize(route string) string {
+	if rt.mode == Sensitive {
+		return trimTrailingSlash(route)
+	}
+	return normalize(route)
+}
+
+// hasUpper returns true if the path has any uppercase letters
+func hasUpper(path string) bool {
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonical path of the matched route, using the slot values from the request.
+// The router lowercases routes, so the path only differs from the request's
+// path in the case of its static segments.
+func canonical(route string, slots radix.Slots) string {
+	var tokens lex.Tokens
+	lexer := lex.New(route)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.ErrorToken, lex.EndToken:
+			path := new(strings.Builder)
+			for _, token := range tokens {
+				path.WriteString(token.Value)
+			}
+			return path.String()
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken:
+			value, ok := lastSlot(slots, token.Slot())
+			if !ok {
+				// Optional and wildcard slots that weren't matched end the path
+				tokens = stripTrail(tokens)
+				continue
+			}
+			tokens = append(tokens, lex.Token{Type: lex.SlotToken, Value: value})
+		default:
+			tokens = append(tokens, token)
+		}
+	}
+}
+
+// lastSlot finds the value of the slot, searching from the end so path slots
+// are found before host slots with the same name
+func lastSlot(slots radix.Slots, key string) (string, bool) {
+	for i := len(slots) - 1; i >= 0; i-- {
+		if slots[i].Key == key {
+			return slots[i].Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,110 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/router"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.Get("/Users/:id", handler("/users/:id")))
+	for _, url := range []string{"/users/aGVsbG8", "/USERS/aGVsbG8", "/Users/aGVsbG8/"} {
+		res, body := serve(t, rt, http.MethodGet, url)
+		is.Equal(200, res.StatusCode)
+		is.Equal("id=aGVsbG8", body)
+	}
+}
+
+func TestCaseSensitive(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.SetCase(router.Sensitive))
+	is.NoErr(rt.Get("/files/README.md", handler("/files/README.md")))
+	is.NoErr(rt.Get("/files/:path*", handler("/files/:path*")))
+	is.NoErr(rt.Named("user", http.MethodGet, "/Users/:id", handler("/Users/:id")))
+	tests := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/files/README.md", 200, ""},
+		{"/files/readme.md", 200, "path=readme.md"},
+		{"/files/Docs/Intro.md", 200, "path=Docs%2FIntro.md"},
+		{"/Users/aGVsbG8", 200, "id=aGVsbG8"},
+		{"/Users/aGVsbG8/", 200, "id=aGVsbG8"},
+		{"/users/aGVsbG8", 404, "404 page not found\n"},
+		{"/USERS/aGVsbG8", 404, "404 page not found\n"},
+	}
+	for _, test := range tests {
+		res, body := serve(t, rt, http.MethodGet, test.url)
+		is.Equal(test.status, res.StatusCode)
+		is.Equal(test.body, body)
+	}
+	url, err := rt.URL("user", map[string]string{"id": "aGVsbG8"})
+	is.NoErr(err)
+	is.Equal("/Users/aGVsbG8", url)
+}
+
+func TestCaseRedirect(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.SetCase(router.Redirect))
+	is.NoErr(rt.Get("/users/:id.:format?", handler("/users/:id.:format?")))
+	is.NoErr(rt.Post("/users", handler("/users")))
+	is.NoErr(rt.Get("/docs/:path*", handler("/docs/:path*")))
+	is.NoErr(rt.Host("{tenant}.example.com").Get("/about/:id", handler("/about/:id")))
+	tests := []struct {
+		method   string
+		url      string
+		status   int
+		location string
+	}{
+		{http.MethodGet, "/users/aGVsbG8", 200, ""},
+		{http.MethodGet, "/Users/aGVsbG8", 301, "/users/aGVsbG8"},
+		{http.MethodGet, "/USERS/aGVsbG8.JSON?page=2", 301, "/users/aGVsbG8.JSON?page=2"},
+		{http.MethodHead, "/Users/aGVsbG8", 301, "/users/aGVsbG8"},
+		{http.MethodGet, "/Users/a%20b/", 301, "/users/a%20b"},
+		{http.MethodGet, "/docs/Guide/Intro.md", 200, ""},
+		{http.MethodGet, "/Docs/Guide/Intro.md", 301, "/docs/Guide/Intro.md"},
+		{http.MethodGet, "/Docs", 301, "/docs"},
+		// Only GET and HEAD requests are redirected
+		{http.MethodPost, "/USERS", 200, ""},
+	}
+	for _, test := range tests {
+		res, _ := serve(t, rt, test.method, test.url)
+		is.Equal(test.status, res.StatusCode)
+		is.Equal(test.location, res.Header.Get("Location"))
+	}
+	// Host slots don't affect the redirect
+	res, _ := serveHost(t, rt, http.MethodGet, "ACME.example.com", "/About/Me")
+	is.Equal(301, res.StatusCode)
+	is.Equal("/about/Me", res.Header.Get("Location"))
+}
+
+func TestSetCase(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.Get("/", handler("/")))
+	err := rt.SetCase(router.Sensitive)
+	is.True(err != nil)
+	is.Equal("router: the case must be set before adding routes", err.Error())
+	err = router.New().SetCase(router.Case(10))
+	is.True(err != nil)
+	is.Equal("router: unknown case Case(10)", err.Error())
+}
+
+func TestParseCase(t *testing.T) {
+	is := is.New(t)
+	for _, c := range []router.Case{router.Insensitive, router.Sensitive, router.Redirect} {
+		parsed, err := router.ParseCase(c.String())
+		is.NoErr(err)
+		is.Equal(c, parsed)
+	}
+	_, err := router.ParseCase("lower")
+	is.True(err != nil)
+	is.Equal(`router: unknown case "lower". Expected insensitive, sensitive or redirect`, err.Error())
+}
@@ -0,0 +1,191 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/livebud/bud/package/middleware"
+	"github.com/livebud/bud/package/router/radix"
+)
+
+// Group of routes that share a prefix and middleware
+type Group struct {
+	router *Router
+	host   *host // nil when the routes match any host
+	prefix string
+	stack  middleware.Middleware
+	err    error // invalid host pattern
+}
+
+// Group routes under a prefix. The routes are wrapped in the middleware.
+func (rt *Router) Group(prefix string, stack ...middleware.Middleware) *Group {
+	return &Group{
+		router: rt,
+		prefix: strings.TrimRight(prefix, "/"),
+		stack:  middleware.Compose(stack...),
+	}
+}
+
+// Host groups routes that only match hosts like {tenant}.example.com. Host
+// slots are returned by Slots along with the path slots. Routes on a matching
+// host are tried before routes on any host.
+func (rt *Router) Host(pattern string, stack ...middleware.Middleware) *Group {
+	host, err := parseHost(pattern)
+	if err == nil {
+		rt.hosts = append(rt.hosts, host)
+	}
+	return &Group{
+		router: rt,
+		host:   host,
+		stack:  middleware.Compose(stack...),
+		err:    err,
+	}
+}
+
+// Mount the handler under the prefix. The handler receives every request
+// within the prefix, with the prefix stripped from the path.
+func (rt *Router) Mount(prefix string, handler http.Handler) error {
+	return rt.Group("").Mount(prefix, handler)
+}
+
+// Group nested routes under a prefix, within this group's prefix and
+// middleware.
+func (g *Group) Group(prefix string, stack ...middleware.Middleware) *Group {
+	return &Group{
+		router: g.router,
+		host:   g.host,
+		prefix: g.prefix + strings.TrimRight(prefix, "/"),
+		stack:  middleware.Compose(g.stack, middleware.Compose(stack...)),
+		err:    g.err,
+	}
+}
+
+// Add a handler to a route within the group
+func (g *Group) Add(method, route string, handler http.Handler) error {
+	if !isMethod(method) {
+		return fmt.Errorf("router: %q is not a valid HTTP method", method)
+	}
+	return g.add(method, route, handler)
+}
+
+// Named adds a handler to a named route within the group
+func (g *Group) Named(name, method, route string, handler http.Handler) error {
+	if name == "" {
+		return fmt.Errorf("router: route %q must have a name", g.join(route))
+	}
+	if existing, ok := g.router.names[name]; ok {
+		return fmt.Errorf("router: %q is already the name of %q", name, existing)
+	}
+	if err := g.Add(method, route, handler); err != nil {
+		return err
+	}
+	g.router.names[name] = g.router.normalize(g.join(route))
+	return nil
+}
+
+// Get route
+func (g *Group) Get(route string, handler http.Handler) error {
+	return g.add(http.MethodGet, route, handler)
+}
+
+// Post route
+func (g *Group) Post(route string, handler http.Handler) error {
+	return g.add(http.MethodPost, route, handler)
+}
+
+// Put route
+func (g *Group) Put(route string, handler http.Handler) error {
+	return g.add(http.MethodPut, route, handler)
+}
+
+// Patch route
+func (g *Group) Patch(route string, handler http.Handler) error {
+	return g.add(http.MethodPatch, route, handler)
+}
+
+// Delete route
+func (g *Group) Delete(route string, handler http.Handler) error {
+	return g.add(http.MethodDelete, route, handler)
+}
+
+// Mount the handler under the prefix within the group. The handler receives
+// every request within the prefix, with the prefix stripped from the path.
+func (g *Group) Mount(prefix string, handler http.Handler) error {
+	route := strings.TrimRight(prefix, "/") + "/:" + mountSlot + "*"
+	handler = mount(handler)
+	for _, method := range methods {
+		if err := g.add(method, route, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Group) add(method, route string, handler http.Handler) error {
+	if g.err != nil {
+		return g.err
+	}
+	trees := g.router.methods
+	if g.host != nil {
+		trees = g.host.methods
+	}
+	route = g.router.normalize(g.join(route))
+	if err := g.router.insert(trees, method, route, g.stack(handler)); err != nil {
+		return err
+	}
+	if method == http.MethodOptions {
+		return nil
+	}
+	// Answer OPTIONS requests for the route within the group's middleware, so
+	// middleware like CORS can respond to preflight requests
+	return g.router.insert(trees, preflight, route, g.stack(noContent))
+}
+
+// preflight holds the OPTIONS handlers for routes added within groups. It's not
+// a valid method token, so requests can't match it directly.
+const preflight = "(preflight)"
+
+// noContent responds to OPTIONS requests that the middleware passed through
+var noContent = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+// join the route to the group's prefix
+func (g *Group) join(route string) string {
+	if route == "/" && g.prefix != "" {
+		return g.prefix
+	}
+	return g.prefix + route
+}
+
+// mountSlot is the wildcard slot that holds the mounted handler's path
+const mountSlot = "mount"
+
+// mount strips the prefix from the request before calling the handler
+func mount(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rest string
+		var slots radix.Slots
+		for _, slot := range Slots(r) {
+			if slot.Key == mountSlot {
+				rest = slot.Value
+				continue
+			}
+			slots = append(slots, slot)
+		}
+		// Keep the trailing slash, since the router strips it before matching
+		urlPath := trimTrailingSlash(r.URL.Path)
+		stripped := r.URL.Path[len(urlPath)-len(rest):]
+		if !strings.HasPrefix(stripped, "/") {
+			stripped = "/" + stripped
+		}
+		r = r.WithContext(context.WithValue(r.Context(), slotsKey{}, slots))
+		u := *r.URL
+		u.Path = stripped
+		u.RawPath = ""
+		r.URL = &u
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,159 @@
+package router_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/router"
+)
+
+// serve a request with the router and return the response body
+func serve(t testing.TB, h http.Handler, method, url string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, url, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	res := rec.Result()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(body)
+}
+
+// tag adds a header to the response to check the middleware ran
+func tag(value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Tag", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	admin := rt.Group("/admin/", tag("admin"))
+	is.NoErr(admin.Get("/", handler("/admin")))
+	is.NoErr(admin.Get("/users/:id", handler("/admin/users/:id")))
+	is.NoErr(admin.Named("admin_post", http.MethodPost, "/posts", handler("/admin/posts")))
+	orgs := admin.Group("/orgs/:org", tag("orgs"))
+	is.NoErr(orgs.Patch("/members/:id", handler("/admin/orgs/:org/members/:id")))
+	is.NoErr(rt.Get("/users/:id", handler("/users/:id")))
+	res, body := serve(t, rt, http.MethodGet, "/admin")
+	is.Equal(200, res.StatusCode)
+	is.Equal([]string{"admin"}, res.Header.Values("X-Tag"))
+	res, body = serve(t, rt, http.MethodGet, "/admin/users/10")
+	is.Equal(200, res.StatusCode)
+	is.Equal("id=10", body)
+	is.Equal([]string{"admin"}, res.Header.Values("X-Tag"))
+	res, body = serve(t, rt, http.MethodPatch, "/admin/orgs/acme/members/10")
+	is.Equal(200, res.StatusCode)
+	is.Equal("id=10&org=acme", body)
+	is.Equal([]string{"admin", "orgs"}, res.Header.Values("X-Tag"))
+	res, body = serve(t, rt, http.MethodGet, "/users/10")
+	is.Equal(200, res.StatusCode)
+	is.Equal("id=10", body)
+	is.Equal(0, len(res.Header.Values("X-Tag")))
+	url, err := rt.URL("admin_post", nil)
+	is.NoErr(err)
+	is.Equal("/admin/posts", url)
+	// Invalid methods
+	err = admin.Add("GOT", "/", handler("/"))
+	is.True(err != nil)
+	is.Equal(`router: "GOT" is not a valid HTTP method`, err.Error())
+}
+
+// echo the path and slots the mounted handler receives
+func TestGroupOptions(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	admin := rt.Group("/admin", tag("admin"))
+	is.NoErr(admin.Post("/users", handler("/admin/users")))
+	orgs := admin.Group("/orgs/:org", tag("orgs"))
+	is.NoErr(orgs.Patch("/members/:id", handler("/admin/orgs/:org/members/:id")))
+	is.NoErr(rt.Post("/login", handler("/login")))
+	// OPTIONS requests run within the middleware of the route's group
+	res, body := serve(t, rt, http.MethodOptions, "/admin/users")
+	is.Equal(204, res.StatusCode)
+	is.Equal("", body)
+	is.Equal("POST, OPTIONS", res.Header.Get("Allow"))
+	is.Equal([]string{"admin"}, res.Header.Values("X-Tag"))
+	res, _ = serve(t, rt, http.MethodOptions, "/admin/orgs/acme/members/10")
+	is.Equal(204, res.StatusCode)
+	is.Equal("PATCH, OPTIONS", res.Header.Get("Allow"))
+	is.Equal([]string{"admin", "orgs"}, res.Header.Values("X-Tag"))
+	res, _ = serve(t, rt, http.MethodOptions, "/login")
+	is.Equal(204, res.StatusCode)
+	is.Equal(0, len(res.Header.Values("X-Tag")))
+	// Other methods are still not allowed
+	res, _ = serve(t, rt, http.MethodGet, "/admin/users")
+	is.Equal(405, res.StatusCode)
+	is.Equal(0, len(res.Header.Values("X-Tag")))
+}
+
+func echo() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.URL.Path))
+		if slots := router.Slots(r).Values().Encode(); slots != "" {
+			w.Write([]byte(" " + slots))
+		}
+		if r.URL.RawQuery != "" {
+			w.Write([]byte("?" + r.URL.RawQuery))
+		}
+	})
+}
+
+func TestMount(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.Mount("/admin", echo()))
+	is.NoErr(rt.Group("/orgs/:org").Mount("/dashboard/", echo()))
+	is.NoErr(rt.Get("/users", handler("/users")))
+	tests := []struct {
+		method string
+		url    string
+		body   string
+	}{
+		{http.MethodGet, "/admin", "GET /"},
+		{http.MethodGet, "/admin/", "GET /"},
+		{http.MethodGet, "/admin/users", "GET /users"},
+		{http.MethodGet, "/admin/users/", "GET /users/"},
+		{http.MethodPost, "/admin/users/10/edit?x=1", "POST /users/10/edit?x=1"},
+		{http.MethodDelete, "/admin/users/10", "DELETE /users/10"},
+		{http.MethodOptions, "/admin/users", "OPTIONS /users"},
+		{http.MethodGet, "/orgs/acme/dashboard/stats", "GET /stats org=acme"},
+		{http.MethodGet, "/users", ""},
+	}
+	for _, test := range tests {
+		res, body := serve(t, rt, test.method, test.url)
+		is.Equal(200, res.StatusCode)
+		is.Equal(test.body, body)
+	}
+	res, _ := serve(t, rt, http.MethodGet, "/administrator")
+	is.Equal(404, res.StatusCode)
+	// Mounts conflict with slots under the same prefix
+	err := rt.Get("/admin/:id", handler("/admin/:id"))
+	is.True(err != nil)
+}
+
+func TestMountRoot(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.Get("/users", handler("/users")))
+	is.NoErr(rt.Mount("/", echo()))
+	res, body := serve(t, rt, http.MethodGet, "/")
+	is.Equal(200, res.StatusCode)
+	is.Equal("GET /", body)
+	res, body = serve(t, rt, http.MethodGet, "/a/b")
+	is.Equal(200, res.StatusCode)
+	is.Equal("GET /a/b", body)
+	// Routes are more specific than the mount
+	res, body = serve(t, rt, http.MethodGet, "/users")
+	is.Equal(200, res.StatusCode)
+	is.Equal("", body)
+}
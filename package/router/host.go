@@ -0,0 +1,101 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/livebud/bud/package/router/radix"
+)
+
+// host is a set of routes that only match hosts like {tenant}.example.com
+type host struct {
+	pattern string
+	re      *regexp.Regexp
+	slots   []string
+	methods map[string]radix.Tree
+}
+
+// parseHost parses a host pattern like {tenant}.example.com. Host slots match
+// a single label of the hostname.
+func parseHost(pattern string) (*host, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("router: host pattern must not be empty")
+	}
+	h := &host{
+		pattern: pattern,
+		methods: map[string]radix.Tree{},
+	}
+	expr := new(strings.Builder)
+	expr.WriteString(`^(?i)`)
+	rest := pattern
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return nil, fmt.Errorf("router: host %q has an unexpected \"}\"", pattern)
+			}
+			expr.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("router: host %q is missing a \"}\"", pattern)
+		}
+		end += start
+		literal, slot := rest[:start], rest[start+1:end]
+		if strings.IndexByte(literal, '}') >= 0 {
+			return nil, fmt.Errorf("router: host %q has an unexpected \"}\"", pattern)
+		}
+		if !isSlotName(slot) {
+			return nil, fmt.Errorf("router: host %q has an invalid slot name %q", pattern, slot)
+		}
+		expr.WriteString(regexp.QuoteMeta(literal))
+		expr.WriteString(`([^.]+)`)
+		h.slots = append(h.slots, slot)
+		rest = rest[end+1:]
+	}
+	expr.WriteString(`$`)
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("router: unable to compile host %q. %w", pattern, err)
+	}
+	h.re = re
+	return h, nil
+}
+
+// Match the hostname, returning the host slots
+func (h *host) Match(hostname string) (radix.Slots, bool) {
+	values := h.re.FindStringSubmatch(hostname)
+	if values == nil {
+		return nil, false
+	}
+	slots := make(radix.Slots, len(h.slots))
+	for i, slot := range h.slots {
+		slots[i] = &radix.Slot{Key: slot, Value: values[i+1]}
+	}
+	return slots, true
+}
+
+// isSlotName returns true if the name is a valid slot name like "tenant"
+func isSlotName(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if c != '_' && (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// stripPort removes the port from the request's host
+func stripPort(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return hostport
+}
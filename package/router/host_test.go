@@ -0,0 +1,78 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/router"
+)
+
+func serveHost(t testing.TB, h http.Handler, method, host, url string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, url, nil)
+	req.Host = host
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	res := rec.Result()
+	return res, rec.Body.String()
+}
+
+func TestHost(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	tenant := rt.Host("{tenant}.example.com", tag("tenant"))
+	is.NoErr(tenant.Get("/", handler("/")))
+	is.NoErr(tenant.Get("/users/:id", handler("/users/:id")))
+	is.NoErr(tenant.Group("/admin").Mount("/", echo()))
+	is.NoErr(rt.Host("api.{region}.example.com").Get("/users/:id", handler("/users/:id")))
+	is.NoErr(rt.Get("/", handler("/")))
+	is.NoErr(rt.Get("/about", handler("/about")))
+	tests := []struct {
+		method string
+		host   string
+		url    string
+		status int
+		body   string
+		tag    string
+	}{
+		{http.MethodGet, "acme.example.com", "/", 200, "tenant=acme", "tenant"},
+		{http.MethodGet, "ACME.example.com:3000", "/users/10", 200, "id=10&tenant=ACME", "tenant"},
+		{http.MethodGet, "acme.example.com", "/admin/settings", 200, "GET /settings tenant=acme", "tenant"},
+		{http.MethodHead, "acme.example.com", "/users/10", 200, "", "tenant"},
+		// Routes on any host are used when the host's routes don't match
+		{http.MethodGet, "acme.example.com", "/about", 200, "", ""},
+		{http.MethodGet, "example.com", "/", 200, "", ""},
+		{http.MethodGet, "example.com", "/users/10", 404, "404 page not found\n", ""},
+		{http.MethodGet, "a.b.example.com", "/users/10", 404, "404 page not found\n", ""},
+		{http.MethodGet, "api.eu.example.com", "/users/10", 200, "id=10&region=eu", ""},
+		{http.MethodPost, "acme.example.com", "/users/10", 405, "Method Not Allowed\n", ""},
+	}
+	for _, test := range tests {
+		res, body := serveHost(t, rt, test.method, test.host, test.url)
+		is.Equal(test.status, res.StatusCode)
+		is.Equal(test.body, body)
+		is.Equal(test.tag, res.Header.Get("X-Tag"))
+	}
+	// Allow lists the methods for the host's routes
+	res, _ := serveHost(t, rt, http.MethodPost, "acme.example.com", "/users/10")
+	is.Equal("GET, HEAD, OPTIONS", res.Header.Get("Allow"))
+}
+
+func TestHostInvalid(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	tests := map[string]string{
+		"":                     `router: host pattern must not be empty`,
+		"{tenant.example.com":  `router: host "{tenant.example.com" is missing a "}"`,
+		"tenant}.example.com":  `router: host "tenant}.example.com" has an unexpected "}"`,
+		"{Tenant}.example.com": `router: host "{Tenant}.example.com" has an invalid slot name "Tenant"`,
+		"{}.example.com":       `router: host "{}.example.com" has an invalid slot name ""`,
+	}
+	for pattern, expect := range tests {
+		err := rt.Host(pattern).Get("/", handler("/"))
+		is.True(err != nil)
+		is.Equal(expect, err.Error())
+	}
+}
@@ -8,12 +8,20 @@ import (
 
 // New lexer
 func New(route string) Lexer {
-	l := &lexer{
-		tokenCh: make(chan Token),
-		input:   route,
+	return &lexer{
+		input: route,
+		state: lexSlash,
+	}
+}
+
+// NewCaseSensitive lexer allows uppercase letters in the route's paths. Slot
+// names must still be lowercase.
+func NewCaseSensitive(route string) Lexer {
+	return &lexer{
+		input: route,
+		state: lexSlash,
+		upper: true,
 	}
-	go l.run()
-	return l
 }
 
 // Lexer interface
@@ -29,24 +37,36 @@ type Lexer interface {
 //	      ^ pos is at "N", width 1 ("N" is 1 byte wide)
 //	^ start is at ":"
 type lexer struct {
-	tokenCh chan Token
-	input   string // buffer containing the full route
-	start   int    // start position of the new token
-	pos     int    // current position in the token stream
-	width   int    // width of the current rune
+	input  string   // buffer containing the full route
+	start  int      // start position of the new token
+	pos    int      // current position in the token stream
+	width  int      // width of the current rune
+	state  stateFn  // next state to run
+	tokens [2]Token // pending tokens, a state emits at most two
+	head   int      // index of the next pending token
+	tail   int      // number of pending tokens
+	upper  bool     // allow uppercase letters in paths
 }
 
-// Token gets the next token
+// Next runs the lexer until it has the next token. Once the lexer is done, it
+// keeps returning the end token.
 func (l *lexer) Next() Token {
-	return <-l.tokenCh
+	for l.head == l.tail {
+		if l.state == nil {
+			return Token{Type: EndToken}
+		}
+		l.head, l.tail = 0, 0
+		l.state = l.state(l)
+	}
+	token := l.tokens[l.head]
+	l.head++
+	return token
 }
 
-// Run the lexer
-func (l *lexer) run() {
-	for state := lexSlash; state != nil; {
-		state = state(l)
-	}
-	close(l.tokenCh)
+// push a pending token
+func (l *lexer) push(token Token) {
+	l.tokens[l.tail] = token
+	l.tail++
 }
 
 const end = 0
@@ -72,7 +92,7 @@ func (l *lexer) step() (r rune) {
 
 func (l *lexer) emit(t token) {
 	value := l.input[l.start:l.pos]
-	l.tokenCh <- Token{Type: t, Value: value}
+	l.push(Token{Type: t, Value: value})
 	l.start = l.pos
 }
 
@@ -83,10 +103,10 @@ func (l *lexer) backup() {
 
 func (l *lexer) errorf(format string, args ...interface{}) stateFn {
 	value := fmt.Sprintf(format, args...)
-	l.tokenCh <- Token{
+	l.push(Token{
 		Type:  ErrorToken,
 		Value: value,
-	}
+	})
 	return nil
 }
 
@@ -119,21 +139,21 @@ func lexText(l *lexer) stateFn {
 		return nil
 	case r == '?' || r == '*':
 		return l.errorf("route %q: unexpected modifier %q", l.input, string(r))
-	case unicode.IsUpper(r):
+	case unicode.IsUpper(r) && !l.upper:
 		return l.errorf("route %q: uppercase letters are not allowed %q", l.input, string(r))
-	case isPath(r):
+	case l.isPath(r):
 		return lexPath
 	default:
 		return l.errorf("route %q: invalid character %q", l.input, string(r))
 	}
 }
 
-func isPath(r rune) bool {
+func (l *lexer) isPath(r rune) bool {
 	switch r {
 	case ' ', ':', '/', '*', '?', end:
 		return false
 	}
-	if unicode.IsUpper(r) {
+	if unicode.IsUpper(r) && !l.upper {
 		return false
 	}
 	return unicode.IsPrint(r)
@@ -141,7 +161,7 @@ func isPath(r rune) bool {
 
 func lexPath(l *lexer) stateFn {
 	r := l.step()
-	for isPath(r) {
+	for l.isPath(r) {
 		r = l.step()
 	}
 	l.backup()
@@ -196,6 +216,9 @@ func lexSlotRest(l *lexer) stateFn {
 	}
 	// After the slot name
 	switch r {
+	case '<':
+		// Support constraints (e.g. :id<int>)
+		return lexConstraint
 	case '?':
 		// Support optional modifiers
 		l.emit(QuestionToken)
@@ -216,6 +239,44 @@ func lexSlotRest(l *lexer) stateFn {
 	}
 }
 
+// Lex the slot's constraint up to the closing ">". Constraints may be regular
+// expressions, so any nested angle brackets need to be balanced.
+func lexConstraint(l *lexer) stateFn {
+	start := l.pos
+	depth := 1
+	for depth > 0 {
+		switch r := l.step(); r {
+		case end:
+			return l.errorf(`route %q: missing ">" after the constraint`, l.input)
+		case '\\':
+			// Skip over escaped characters like \>
+			l.step()
+		case '<':
+			depth++
+		case '>':
+			depth--
+		}
+	}
+	if l.pos-start == 1 {
+		return l.errorf(`route %q: empty constraint "<>"`, l.input)
+	}
+	// After the constraint
+	switch r := l.step(); r {
+	case '?':
+		l.emit(QuestionToken)
+		return lexQuestion
+	case '*':
+		l.emit(StarToken)
+		return lexStar
+	case '.', '/', end:
+		l.backup()
+		l.emit(SlotToken)
+		return lexText
+	default:
+		return l.errorf(`route %q: invalid character %q after the constraint`, l.input, string(r))
+	}
+}
+
 func lexQuestion(l *lexer) stateFn {
 	// Expect End after
 	switch r := l.step(); r {
@@ -93,4 +93,52 @@ var tests = []struct {
 	{input: "/:sLot", err: `route "/:sLot": uppercase letters are not allowed "L"`},
 	{input: "/:sloT", err: `route "/:sloT": uppercase letters are not allowed "T"`},
 	{input: "/:sloT/", err: `route "/:sloT/": uppercase letters are not allowed "T"`},
+	// Constraints
+	{input: "/users/:id<int>", expect: `slash:"/" path:"users" slash:"/" slot:":id<int>"`},
+	{input: "/users/:id<int>/edit", expect: `slash:"/" path:"users" slash:"/" slot:":id<int>" slash:"/" path:"edit"`},
+	{input: "/users/:id<int>.:format?", expect: `slash:"/" path:"users" slash:"/" slot:":id<int>" path:"." question:":format?"`},
+	{input: "/posts/:slug<[a-z0-9-]+>", expect: `slash:"/" path:"posts" slash:"/" slot:":slug<[a-z0-9-]+>"`},
+	{input: "/posts/:slug<[A-Z]+>", expect: `slash:"/" path:"posts" slash:"/" slot:":slug<[A-Z]+>"`},
+	{input: "/:uuid<uuid>?", expect: `slash:"/" question:":uuid<uuid>?"`},
+	{input: "/files/:path<.+\\.txt>*", expect: `slash:"/" path:"files" slash:"/" star:":path<.+\\.txt>*"`},
+	{input: "/:n<(?P<n>[0-9]+)>", expect: `slash:"/" slot:":n<(?P<n>[0-9]+)>"`},
+	{input: "/:n<\\>>", expect: `slash:"/" slot:":n<\\>>"`},
+	{input: "/:id<>", err: `route "/:id<>": empty constraint "<>"`},
+	{input: "/:id<int", err: `route "/:id<int": missing ">" after the constraint`},
+	{input: "/:id<int>x", err: `route "/:id<int>x": invalid character "x" after the constraint`},
+	{input: "/:id<int>?/a", err: `route "/:id<int>?/a": optional "?" must be at the end`},
+}
+
+func TestCaseSensitive(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+		err    string
+	}{
+		{input: "/Explore", expect: `slash:"/" path:"Explore"`},
+		{input: "/files/README.md", expect: `slash:"/" path:"files" slash:"/" path:"README.md"`},
+		{input: "/Users/:id.JSON", expect: `slash:"/" path:"Users" slash:"/" slot:":id" path:".JSON"`},
+		{input: "/:Slot", err: `route "/:Slot": first letter after ":" must be a lowercase Latin letter`},
+		{input: "/:sLot", err: `route "/:sLot": uppercase letters are not allowed "L"`},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			is := is.New(t)
+			l := lex.NewCaseSensitive(test.input)
+			var tokens lex.Tokens
+			for {
+				token := l.Next()
+				switch token.Type {
+				case lex.ErrorToken:
+					is.Equal(test.err, token.Value)
+					return
+				case lex.EndToken:
+					is.Equal(test.expect, tokens.String())
+					return
+				default:
+					tokens = append(tokens, token)
+				}
+			}
+		})
+	}
 }
@@ -33,6 +33,37 @@ func (t Token) String() string {
 	return fmt.Sprintf("%s:%q", t.Type, t.Value)
 }
 
+// Slot returns the name of a slot without its constraint or modifier (e.g.
+// "id" for ":id<int>?"). It returns an empty string for other tokens.
+func (t Token) Slot() string {
+	switch t.Type {
+	case SlotToken, QuestionToken, StarToken:
+	default:
+		return ""
+	}
+	name := strings.TrimPrefix(t.Value, ":")
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		return name[:i]
+	}
+	return strings.TrimRight(name, "?*")
+}
+
+// Constraint returns the slot's constraint (e.g. "int" for ":id<int>"). It
+// returns an empty string if the slot doesn't have a constraint.
+func (t Token) Constraint() string {
+	switch t.Type {
+	case SlotToken, QuestionToken, StarToken:
+	default:
+		return ""
+	}
+	start := strings.IndexByte(t.Value, '<')
+	end := strings.LastIndexByte(t.Value, '>')
+	if start < 0 || end < start {
+		return ""
+	}
+	return t.Value[start+1 : end]
+}
+
 // Tokens is a list of tokens
 type Tokens []Token
 
@@ -190,3 +190,21 @@ func TestSplit(t *testing.T) {
 	is.Equal(parts[1].At(1), ":id")
 	is.Equal(parts[1].At(2), "")
 }
+
+func TestSlot(t *testing.T) {
+	is := is.New(t)
+	toks := tokens(t, "/users/:id<int>/:slug<[a-z]+>?")
+	is.Equal(toks[3].Slot(), "id")
+	is.Equal(toks[3].Constraint(), "int")
+	is.Equal(toks[5].Slot(), "slug")
+	is.Equal(toks[5].Constraint(), "[a-z]+")
+	toks = tokens(t, "/:id.:format?")
+	is.Equal(toks[0].Slot(), "")
+	is.Equal(toks[1].Slot(), "id")
+	is.Equal(toks[1].Constraint(), "")
+	is.Equal(toks[3].Slot(), "format")
+	is.Equal(toks[3].Constraint(), "")
+	toks = tokens(t, "/:path<.+>*")
+	is.Equal(toks[1].Slot(), "path")
+	is.Equal(toks[1].Constraint(), ".+")
+}
@@ -4,7 +4,7 @@ import "github.com/livebud/bud/package/router/lex"
 
 func Parse(route string) (tokens []lex.Token) {
 	lexer := lex.New(route)
-	for token := lexer.Next(); token.Type != lex.EndToken; {
+	for token := lexer.Next(); token.Type != lex.EndToken && token.Type != lex.ErrorToken; token = lexer.Next() {
 		tokens = append(tokens, token)
 	}
 	return tokens
@@ -0,0 +1,99 @@
+package radix
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/livebud/bud/package/router/lex"
+)
+
+// Check the value of a slot against its constraint
+type checkFn func(value string) bool
+
+// Constraints that can be referenced by name. Other constraints are regular
+// expressions that need to match the whole value.
+var constraints = map[string]checkFn{
+	"int":  isInt,
+	"uint": isUint,
+	"uuid": isUUID,
+}
+
+// rank the constraint by how specific it is. Slots with overlapping
+// constraints on the same level are tried from the lowest rank to the highest.
+// Regular expressions are tried in the order they were inserted.
+func rank(expr string) int {
+	switch expr {
+	case "uuid":
+		return 1
+	case "uint":
+		return 2
+	case "int":
+		return 3
+	case "":
+		return 5
+	default:
+		return 4
+	}
+}
+
+// constraint compiles the slot's constraint into a check function. Slots
+// without a constraint accept any value.
+func constraint(token lex.Token) (checkFn, error) {
+	expr := token.Constraint()
+	if expr == "" {
+		return nil, nil
+	}
+	if check, ok := constraints[expr]; ok {
+		return check, nil
+	}
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("radix: invalid constraint %q in slot %q. %w", expr, token.Slot(), err)
+	}
+	return re.MatchString, nil
+}
+
+// isInt checks for an optionally signed base 10 integer
+func isInt(value string) bool {
+	if len(value) > 1 && value[0] == '-' {
+		value = value[1:]
+	}
+	return isUint(value)
+}
+
+// isUint checks for an unsigned base 10 integer
+func isUint(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID checks for a UUID like 123e4567-e89b-12d3-a456-426614174000
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if value[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(value[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -4,26 +4,52 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/livebud/bud/package/router/lex"
 )
 
-// New radix tree
+// New radix tree that matches paths regardless of case
 func New() Tree {
 	return &tree{}
 }
 
+// NewCaseSensitive radix tree that allows uppercase letters in routes and only
+// matches paths with the same case
+func NewCaseSensitive() Tree {
+	return &tree{sensitive: true}
+}
+
 // Tree interface
 type Tree interface {
 	Insert(route string, handler http.Handler) error
-	Match(path string) (*Match, bool)
+	Match(path string) (Match, bool)
 	String() string
 }
 
 // Slots is a list of key value pairs
 type Slots []*Slot
 
+// Get the value of the slot with the given key
+func (slots Slots) Get(key string) (string, bool) {
+	for _, slot := range slots {
+		if slot.Key == key {
+			return slot.Value, true
+		}
+	}
+	return "", false
+}
+
+// Values returns the slots as URL values
+func (slots Slots) Values() url.Values {
+	values := make(url.Values, len(slots))
+	for _, slot := range slots {
+		values.Set(slot.Key, slot.Value)
+	}
+	return values
+}
+
 // Slot is a key value pair
 type Slot struct {
 	Key   string
@@ -37,8 +63,8 @@ type Match struct {
 	Slots   Slots
 }
 
-// Match the path to a route
-type matchFn func(path string) (index int, slots Slots)
+// Match the path to a route, appending any matched slots to slots
+type matchFn func(path string, slots Slots) (index int, matched Slots)
 
 type node struct {
 	tokens   lex.Tokens   // tokens that make up part or all of the route
@@ -71,16 +97,23 @@ func (n *node) priority() (priority int) {
 }
 
 type tree struct {
-	root *node
+	root      *node
+	sensitive bool
 }
 
 func (t *tree) Insert(route string, handler http.Handler) error {
 	lexer := lex.New(route)
+	if t.sensitive {
+		lexer = lex.NewCaseSensitive(route)
+	}
 	var tokens lex.Tokens
 	for {
 		token := lexer.Next()
 		switch token.Type {
 		case lex.QuestionToken:
+			if _, err := constraint(token); err != nil {
+				return err
+			}
 			// Each optional tokens insert two routes
 			if err := t.insert(stripTokenTrail(tokens), route, handler); err != nil {
 				return err
@@ -91,6 +124,9 @@ func (t *tree) Insert(route string, handler http.Handler) error {
 				Type:  lex.SlotToken,
 			})
 		case lex.StarToken:
+			if _, err := constraint(token); err != nil {
+				return err
+			}
 			// Each optional tokens insert two routes
 			if err := t.insert(stripTokenTrail(tokens), route, handler); err != nil {
 				return err
@@ -102,6 +138,11 @@ func (t *tree) Insert(route string, handler http.Handler) error {
 		case lex.EndToken:
 			// Done parsing the route
 			return t.insert(tokens, route, handler)
+		case lex.SlotToken:
+			if _, err := constraint(token); err != nil {
+				return err
+			}
+			tokens = append(tokens, token)
 		default:
 			tokens = append(tokens, token)
 		}
@@ -136,7 +177,7 @@ func (t *tree) insert(tokens lex.Tokens, route string, handler http.Handler) err
 	if t.root == nil {
 		t.root = &node{
 			tokens:  tokens,
-			match:   matcher(tokens),
+			match:   t.matcher(tokens),
 			route:   route,
 			handler: handler,
 		}
@@ -154,7 +195,7 @@ func (t *tree) insertAt(parent *node, tokens lex.Tokens, route string, handler h
 	// If longest common prefix is not the same length as the node path, We need
 	// to split the node path into parent and child to prepare for another child.
 	if lcp < parent.tokens.Size() {
-		parent = splitAt(parent, lcp)
+		parent = t.splitAt(parent, lcp)
 		// This set of tokens are already in the tree
 		// E.g. We've inserted "/a", "/b", then "/". "/" will already be in the tree
 		// but not have a handler
@@ -165,7 +206,7 @@ func (t *tree) insertAt(parent *node, tokens lex.Tokens, route string, handler h
 		}
 		err := insertChild(parent, &node{
 			tokens:  parts[1],
-			match:   matcher(parts[1]),
+			match:   t.matcher(parts[1]),
 			route:   route,
 			handler: handler,
 		})
@@ -201,21 +242,21 @@ func (t *tree) insertAt(parent *node, tokens lex.Tokens, route string, handler h
 	// part of the path.
 	return insertChild(parent, &node{
 		tokens:  parts[1],
-		match:   matcher(parts[1]),
+		match:   t.matcher(parts[1]),
 		route:   route,
 		handler: handler,
 	})
 }
 
 // Split the single node into a parent and child node
-func splitAt(parent *node, at int) *node {
+func (t *tree) splitAt(parent *node, at int) *node {
 	parts := parent.tokens.Split(at)
 	if len(parts) == 1 {
 		return parent
 	}
 	child := &node{
 		tokens:   parts[1],
-		match:    matcher(parts[1]),
+		match:    t.matcher(parts[1]),
 		route:    parent.route,
 		handler:  parent.handler,
 		children: parent.children,
@@ -227,7 +268,7 @@ func splitAt(parent *node, at int) *node {
 	insertChild(parent, child)
 	// Split the tokens up and recompile the match function
 	parent.tokens = parts[0]
-	parent.match = matcher(parts[0])
+	parent.match = t.matcher(parts[0])
 	return parent
 }
 
@@ -252,35 +293,41 @@ func insertWild(parent *node, child *node) error {
 			parent.wilds = append(parent.wilds[:i], append([]*node{child}, parent.wilds[i:]...)...)
 			return nil
 		}
-		// Don't allow /:id and /:hi on the same level.
 		if childp == wildp {
-			return fmt.Errorf("radix: ambiguous routes %q and %q", child.route, wild.route)
+			childc, wildc := child.tokens[0].Constraint(), wild.tokens[0].Constraint()
+			// Don't allow /:id and /:hi or /:id<int> and /:n<int> on the same level.
+			if childc == wildc {
+				return fmt.Errorf("radix: ambiguous routes %q and %q", child.route, wild.route)
+			}
+			// Prioritize more specific constraints, so /:id<int> is tried before
+			// /:slug on the same level.
+			if rank(childc) < rank(wildc) {
+				parent.wilds = append(parent.wilds[:i], append([]*node{child}, parent.wilds[i:]...)...)
+				return nil
+			}
 		}
 	}
 	parent.wilds = append(parent.wilds, child)
 	return nil
 }
 
-// Match the path to a route in the tree
-func (t *tree) Match(path string) (*Match, bool) {
+// Match the path to a route in the tree. Matching static routes doesn't
+// allocate.
+func (t *tree) Match(path string) (Match, bool) {
 	// A tree without any routes shouldn't panic
 	if t.root == nil {
-		return nil, false
+		return Match{}, false
 	}
-	match := t.match(t.root, path, Slots{})
-	if match == nil {
-		return nil, false
-	}
-	return match, true
+	return t.match(t.root, path, nil)
 }
 
 // Turn the tokens into a matcher
-func matcher(tokens lex.Tokens) matchFn {
+func (t *tree) matcher(tokens lex.Tokens) matchFn {
 	var matchers []matchFn
 	for _, token := range tokens {
 		switch token.Type {
 		case lex.PathToken, lex.SlashToken:
-			matchers = append(matchers, matchExact(token))
+			matchers = append(matchers, matchExact(token, t.sensitive))
 		case lex.SlotToken:
 			matchers = append(matchers, matchSlot(token))
 		case lex.StarToken:
@@ -292,39 +339,43 @@ func matcher(tokens lex.Tokens) matchFn {
 
 // Compose the match functions into one function
 func compose(matchers []matchFn) matchFn {
-	return func(path string) (index int, slots Slots) {
+	return func(path string, slots Slots) (index int, matched Slots) {
 		for _, match := range matchers {
-			i, matchSlots := match(path)
+			i, matchSlots := match(path, slots)
 			if i == -1 {
-				return -1, matchSlots
+				return -1, slots
 			}
 			path = path[i:]
 			index += i
-			slots = append(slots, matchSlots...)
+			slots = matchSlots
 		}
 		return index, slots
 	}
 }
 
-// Match a slot exactly (/users)
-func matchExact(token lex.Token) matchFn {
+// Match a path exactly (/users). Case-insensitive paths also match /Users.
+func matchExact(token lex.Token, sensitive bool) matchFn {
 	route := token.Value
 	rlen := len(route)
-	return func(path string) (index int, slots Slots) {
+	return func(path string, slots Slots) (index int, matched Slots) {
 		if len(path) < rlen {
-			return -1, nil
+			return -1, slots
+		}
+		if sensitive && path[:rlen] != route {
+			return -1, slots
 		}
-		if !strings.EqualFold(path[:rlen], route) {
-			return -1, nil
+		if !sensitive && !strings.EqualFold(path[:rlen], route) {
+			return -1, slots
 		}
-		return rlen, nil
+		return rlen, slots
 	}
 }
 
-// Match a slot (/:id)
+// Match a slot (/:id) that satisfies its constraint (/:id<int>)
 func matchSlot(token lex.Token) matchFn {
-	slotKey := token.Value[1:]
-	return func(path string) (index int, slots Slots) {
+	slotKey := token.Slot()
+	check, _ := constraint(token)
+	return func(path string, slots Slots) (index int, matched Slots) {
 		lpath := len(path)
 		for i := 0; i < lpath; i++ {
 			if path[i] == '.' || path[i] == '/' {
@@ -333,62 +384,65 @@ func matchSlot(token lex.Token) matchFn {
 			index++
 		}
 		if index == 0 {
-			return -1, nil
+			return -1, slots
 		}
-		return index, Slots{{
+		if check != nil && !check(path[:index]) {
+			return -1, slots
+		}
+		return index, append(slots, &Slot{
 			Key:   slotKey,
 			Value: path[:index],
-		}}
+		})
 	}
 }
 
 // Match a star (e.g. /:path*)
 func matchStar(token lex.Token) matchFn {
-	lvalue := len(token.Value)
-	slotKey := token.Value[1 : lvalue-1]
-	return func(path string) (index int, slots Slots) {
-		return len(path), Slots{{
+	slotKey := token.Slot()
+	check, _ := constraint(token)
+	return func(path string, slots Slots) (index int, matched Slots) {
+		if check != nil && !check(path) {
+			return -1, slots
+		}
+		return len(path), append(slots, &Slot{
 			Key:   slotKey,
 			Value: path,
-		}}
+		})
 	}
 }
 
 // Match the node
-func (t *tree) match(node *node, path string, slots Slots) *Match {
-	index, matchSlots := node.match(path)
+func (t *tree) match(node *node, path string, slots Slots) (Match, bool) {
+	index, slots := node.match(path, slots)
 	if index < 0 {
-		return nil
+		return Match{}, false
 	}
 	path = path[index:]
-	if matchSlots != nil {
-		slots = append(slots, matchSlots...)
-	}
 	// No more path, we're done!
 	if path == "" {
 		// At a junction node, but this node isn't a route, so it's not a match
 		if node.handler == nil {
-			return nil
+			return Match{}, false
 		}
-		return &Match{
+		return Match{
 			Handler: node.handler,
 			Route:   node.route,
 			Slots:   slots,
-		}
+		}, true
 	}
 	// First try matching the children
 	for _, child := range node.children {
-		if match := t.match(child, path, slots); match != nil {
-			return match
+		if match, ok := t.match(child, path, slots); ok {
+			return match, true
 		}
 	}
 	// Next try matching the wild children
 	for _, wild := range node.wilds {
-		if match := t.match(wild, path, slots); match != nil {
-			return match
+		if match, ok := t.match(wild, path, slots); ok {
+			return match, true
 		}
 	}
-	return nil
+	return Match{}, false
 }
 
 func (t *tree) String() string {
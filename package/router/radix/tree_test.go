@@ -14,8 +14,9 @@ import (
 )
 
 type test struct {
-	inserts  []*insert
-	requests []*request
+	inserts   []*insert
+	requests  []*request
+	sensitive bool
 }
 
 type insert struct {
@@ -45,6 +46,9 @@ func ok(t testing.TB, test *test) {
 	is := is.New(t)
 	is.Helper()
 	tree := radix.New()
+	if test.sensitive {
+		tree = radix.NewCaseSensitive()
+	}
 	for _, insert := range test.inserts {
 		err := tree.Insert(insert.route, handler(insert.route))
 		if err != nil {
@@ -366,3 +370,123 @@ func TestNoRoutes(t *testing.T) {
 		},
 	})
 }
+
+func TestConstraints(t *testing.T) {
+	// Order shouldn't matter
+	okp(t, &test{
+		inserts: []*insert{
+			{route: "/users/new"},
+			{route: "/users/:id<int>"},
+			{route: "/users/:id<int>/edit"},
+			{route: "/users/:slug"},
+			{route: "/posts/:uuid<uuid>"},
+			{route: "/posts/:slug<[a-z0-9-]+>"},
+			{route: "/v.:major<uint>.:minor<uint>"},
+		},
+		requests: []*request{
+			{path: "/users/new", route: "/users/new"},
+			{path: "/users/10", route: "/users/:id<int>", slots: "id=10"},
+			{path: "/users/-10", route: "/users/:id<int>", slots: "id=-10"},
+			{path: "/users/10/edit", route: "/users/:id<int>/edit", slots: "id=10"},
+			{path: "/users/alice", route: "/users/:slug", slots: "slug=alice"},
+			{path: "/users/alice/edit", nomatch: true},
+			{path: "/posts/123e4567-e89b-12d3-a456-426614174000", route: "/posts/:uuid<uuid>", slots: "uuid=123e4567-e89b-12d3-a456-426614174000"},
+			{path: "/posts/123E4567-E89B-12D3-A456-426614174000", route: "/posts/:uuid<uuid>", slots: "uuid=123E4567-E89B-12D3-A456-426614174000"},
+			{path: "/posts/hello-world", route: "/posts/:slug<[a-z0-9-]+>", slots: "slug=hello-world"},
+			{path: "/posts/Hello", nomatch: true},
+			{path: "/v.1.2", route: "/v.:major<uint>.:minor<uint>", slots: "major=1&minor=2"},
+			{path: "/v.1.x", nomatch: true},
+		},
+	})
+}
+
+func TestConstraintOptional(t *testing.T) {
+	ok(t, &test{
+		inserts: []*insert{
+			{route: "/users/:id<int>.:format<json|xml>?"},
+			{route: "/files/:path<.+\\.txt>*"},
+		},
+		requests: []*request{
+			{path: "/users/10", route: "/users/:id<int>.:format<json|xml>?", slots: "id=10"},
+			{path: "/users/10.json", route: "/users/:id<int>.:format<json|xml>?", slots: "format=json&id=10"},
+			{path: "/users/10.html", nomatch: true},
+			{path: "/files", route: "/files/:path<.+\\.txt>*"},
+			{path: "/files/a/b.txt", route: "/files/:path<.+\\.txt>*", slots: "path=a/b.txt"},
+			{path: "/files/a/b.png", nomatch: true},
+		},
+	})
+}
+
+func TestConstraintAmbiguous(t *testing.T) {
+	ok(t, &test{
+		inserts: []*insert{
+			{route: "/:id<int>"},
+			{route: "/:n<int>", err: `radix: ambiguous routes "/:n<int>" and "/:id<int>"`},
+			{route: "/:slug"},
+			{route: "/:name", err: `radix: ambiguous routes "/:name" and "/:slug"`},
+			{route: "/:uuid<uuid>"},
+			{route: "/:bad<[a-z>", err: "radix: invalid constraint \"[a-z\" in slot \"bad\". error parsing regexp: missing closing ]: `[a-z)$`"},
+		},
+		requests: []*request{
+			{path: "/10", route: "/:id<int>", slots: "id=10"},
+			{path: "/123e4567-e89b-12d3-a456-426614174000", route: "/:uuid<uuid>", slots: "uuid=123e4567-e89b-12d3-a456-426614174000"},
+			{path: "/hello", route: "/:slug", slots: "slug=hello"},
+		},
+	})
+}
+
+func TestConstraintRank(t *testing.T) {
+	// Order shouldn't matter
+	okp(t, &test{
+		inserts: []*insert{
+			{route: "/:n<int>"},
+			{route: "/:u<uint>"},
+			{route: "/:uuid<uuid>"},
+			{route: "/:s<[a-z0-9-]+>"},
+			{route: "/:any"},
+		},
+		requests: []*request{
+			{path: "/1", route: "/:u<uint>", slots: "u=1"},
+			{path: "/-1", route: "/:n<int>", slots: "n=-1"},
+			{path: "/123e4567-e89b-12d3-a456-426614174000", route: "/:uuid<uuid>", slots: "uuid=123e4567-e89b-12d3-a456-426614174000"},
+			{path: "/a-1", route: "/:s<[a-z0-9-]+>", slots: "s=a-1"},
+			{path: "/A", route: "/:any", slots: "any=A"},
+		},
+	})
+}
+
+func TestCaseSensitive(t *testing.T) {
+	okp(t, &test{
+		sensitive: true,
+		inserts: []*insert{
+			{route: "/users"},
+			{route: "/Users"},
+			{route: "/files/README.md"},
+			{route: "/files/:path*"},
+			{route: "/links/:id"},
+		},
+		requests: []*request{
+			{path: "/users", route: "/users"},
+			{path: "/Users", route: "/Users"},
+			{path: "/USERS", nomatch: true},
+			{path: "/files/README.md", route: "/files/README.md"},
+			{path: "/files/readme.md", route: "/files/:path*", slots: "path=readme.md"},
+			{path: "/files/Docs/A.md", route: "/files/:path*", slots: "path=Docs/A.md"},
+			{path: "/links/aGVsbG8", route: "/links/:id", slots: "id=aGVsbG8"},
+			{path: "/Links/aGVsbG8", nomatch: true},
+		},
+	})
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	ok(t, &test{
+		inserts: []*insert{
+			{route: "/users/:id"},
+			{route: "/Users", err: `route "/Users": uppercase letters are not allowed "U"`},
+		},
+		requests: []*request{
+			{path: "/users/aGVsbG8", route: "/users/:id", slots: "id=aGVsbG8"},
+			{path: "/USERS/aGVsbG8", route: "/users/:id", slots: "id=aGVsbG8"},
+		},
+	})
+}
@@ -1,23 +1,35 @@
 package router
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"unicode"
 
+	"github.com/felixge/httpsnoop"
 	"github.com/livebud/bud/package/router/radix"
 )
 
 // New router
 func New() *Router {
+	methods := map[string]radix.Tree{}
 	return &Router{
-		methods: map[string]radix.Tree{},
+		methods: methods,
+		any:     []*table{{methods: methods}},
+		names:   map[string]string{},
 	}
 }
 
 // Router struct
 type Router struct {
 	methods map[string]radix.Tree
+	any     []*table          // routes on any host
+	hosts   []*host           // routes that only match certain hosts
+	names   map[string]string // name => route
+	mode    Case              // how the case of paths is treated
 }
 
 var _ http.Handler = (*Router)(nil)
@@ -30,21 +42,22 @@ func (rt *Router) Add(method, route string, handler http.Handler) error {
 	return rt.add(method, route, handler)
 }
 
+// Named adds a handler to a route with a name. The name can be passed to URL
+// to build a path to the route.
+func (rt *Router) Named(name, method, route string, handler http.Handler) error {
+	return rt.Group("").Named(name, method, route, handler)
+}
+
 func (rt *Router) add(method, route string, handler http.Handler) error {
-	if route == "/" {
-		return rt.insert(method, route, handler)
-	}
-	// Trim any trailing slash and lowercase the route
-	route = strings.TrimRight(strings.ToLower(route), "/")
-	return rt.insert(method, route, handler)
+	return rt.insert(rt.methods, method, rt.normalize(route), handler)
 }
 
 // Insert the route into the method's radix tree
-func (rt *Router) insert(method, route string, handler http.Handler) error {
-	if _, ok := rt.methods[method]; !ok {
-		rt.methods[method] = radix.New()
+func (rt *Router) insert(trees map[string]radix.Tree, method, route string, handler http.Handler) error {
+	if _, ok := trees[method]; !ok {
+		trees[method] = rt.tree()
 	}
-	return rt.methods[method].Insert(route, handler)
+	return trees[method].Insert(route, handler)
 }
 
 // Get route
@@ -72,40 +85,238 @@ func (rt *Router) Delete(route string, handler http.Handler) error {
 	return rt.add(http.MethodDelete, route, handler)
 }
 
+var notFound = http.NotFoundHandler()
+
 func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	handler := rt.Middleware(http.NotFoundHandler())
-	handler.ServeHTTP(w, r)
+	rt.serve(w, r, notFound)
 }
 
 // Middleware implements the router middleware
 func (rt *Router) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tree, ok := rt.methods[r.Method]
-		if !ok {
+		rt.serve(w, r, next)
+	})
+}
+
+// serve the request with the matching route or call next if there isn't one.
+// Static routes are served without allocating.
+func (rt *Router) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	// Strip any trailing slash (e.g. /users/ => /users)
+	urlPath := trimTrailingSlash(r.URL.Path)
+	tables := rt.tables(r.Host)
+	match, ok := find(tables, r.Method, urlPath)
+	if !ok && r.Method == http.MethodHead {
+		// Serve HEAD requests from the GET handler without a body
+		if match, ok = find(tables, http.MethodGet, urlPath); ok {
+			w = headWriter(w)
+		}
+	}
+	if !ok {
+		// Check if the path matches a route with another method
+		allowed := allow(tables, urlPath)
+		if len(allowed) == 0 {
 			next.ServeHTTP(w, r)
 			return
 		}
-		// Strip any trailing slash (e.g. /users/ => /users)
-		urlPath := trimTrailingSlash(r.URL.Path)
-		// Match the path
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if r.Method == http.MethodOptions {
+			// Respond within the middleware of the route's group
+			if match, ok := find(tables, preflight, urlPath); ok {
+				track(r, match.Route)
+				match.Handler.ServeHTTP(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	track(r, match.Route)
+	// Redirect to the lowercase path when the case doesn't match the route
+	if rt.mode == Redirect && (r.Method == http.MethodGet || r.Method == http.MethodHead) && hasUpper(urlPath) {
+		if path := canonical(match.Route, match.Slots); path != urlPath {
+			location := url.URL{Path: path, RawQuery: r.URL.RawQuery}
+			http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+			return
+		}
+	}
+	// Add the slots to the request context. They're kept out of the query
+	// string so they can't be spoofed by a query parameter with the same name.
+	if len(match.Slots) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), slotsKey{}, match.Slots))
+	}
+	// Call the handler
+	match.Handler.ServeHTTP(w, r)
+}
+
+// table of routes along with the slots that matched the request's host
+type table struct {
+	slots   radix.Slots
+	methods map[string]radix.Tree
+}
+
+// tables returns the routes that can serve the host. Routes on a matching host
+// are tried before routes on any host.
+func (rt *Router) tables(hostport string) (tables []*table) {
+	if len(rt.hosts) == 0 {
+		return rt.any
+	}
+	hostname := stripPort(hostport)
+	for _, host := range rt.hosts {
+		if slots, ok := host.Match(hostname); ok {
+			tables = append(tables, &table{slots, host.methods})
+		}
+	}
+	if len(tables) == 0 {
+		return rt.any
+	}
+	return append(tables, rt.any...)
+}
+
+// find the first route that matches the method and path. Host slots come
+// before path slots.
+func find(tables []*table, method, urlPath string) (radix.Match, bool) {
+	for _, table := range tables {
+		tree, ok := table.methods[method]
+		if !ok {
+			continue
+		}
 		match, ok := tree.Match(urlPath)
 		if !ok {
-			next.ServeHTTP(w, r)
-			return
+			continue
+		}
+		if len(table.slots) > 0 {
+			match.Slots = append(append(radix.Slots{}, table.slots...), match.Slots...)
 		}
-		// Add the slots
-		if len(match.Slots) > 0 {
-			query := r.URL.Query()
-			for _, slot := range match.Slots {
-				query.Set(slot.Key, slot.Value)
+		return match, true
+	}
+	return radix.Match{}, false
+}
+
+// methods in the order they're listed in the Allow header
+var methods = [...]string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// allow returns the methods that have a route matching the path. GET routes
+// also allow HEAD and every matching path allows OPTIONS.
+func allow(tables []*table, urlPath string) (allow []string) {
+	var allowed [len(methods)]bool
+	found := false
+	for _, table := range tables {
+		for i, method := range methods {
+			if allowed[i] {
+				continue
+			}
+			tree, ok := table.methods[method]
+			if !ok {
+				continue
+			}
+			if _, ok := tree.Match(urlPath); ok {
+				allowed[i] = true
+				found = true
 			}
-			r.URL.RawQuery = query.Encode()
 		}
-		// Call the handler
-		match.Handler.ServeHTTP(w, r)
+	}
+	if !found {
+		return nil
+	}
+	for i, method := range methods {
+		switch {
+		case allowed[i],
+			method == http.MethodHead && allowed[0], // GET
+			method == http.MethodOptions:
+			allow = append(allow, method)
+		}
+	}
+	return allow
+}
+
+// headWriter discards the body of a GET handler serving a HEAD request, while
+// keeping interfaces like http.Flusher and http.Hijacker that w implements
+func headWriter(w http.ResponseWriter) http.ResponseWriter {
+	return httpsnoop.Wrap(w, httpsnoop.Hooks{
+		Write: func(httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+			return func(p []byte) (int, error) {
+				return len(p), nil
+			}
+		},
+		ReadFrom: func(httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+			return func(src io.Reader) (int64, error) {
+				return io.Copy(io.Discard, src)
+			}
+		},
 	})
 }
 
+type routeKey struct{}
+
+// Track returns a request that records the route the router matches. Middleware
+// in front of the router can pass the request on and then read the route with
+// Route.
+func Track(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(routeKey{}).(*string); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, new(string)))
+}
+
+// track records the route for middleware in front of the router
+func track(r *http.Request, route string) {
+	if tracked, ok := r.Context().Value(routeKey{}).(*string); ok {
+		*tracked = route
+	}
+}
+
+// Route returns the route that matched a tracked request, like "/users/:id".
+// It returns an empty string if no route matched or the request wasn't tracked.
+func Route(r *http.Request) string {
+	route, ok := r.Context().Value(routeKey{}).(*string)
+	if !ok {
+		return ""
+	}
+	return *route
+}
+
+type slotsKey struct{}
+
+// Slots returns the path slots that the router matched for this request
+func Slots(r *http.Request) radix.Slots {
+	slots, _ := r.Context().Value(slotsKey{}).(radix.Slots)
+	return slots
+}
+
+// normalize trims any trailing slash and lowercases the route. Slot
+// constraints like :slug<[A-Z]+> keep their case.
+func normalize(route string) string {
+	if route == "/" {
+		return route
+	}
+	out := new(strings.Builder)
+	depth := 0
+	for _, r := range route {
+		switch {
+		case r == '<':
+			depth++
+		case r == '>' && depth > 0:
+			depth--
+		case depth == 0:
+			r = unicode.ToLower(r)
+		}
+		out.WriteRune(r)
+	}
+	return strings.TrimRight(out.String(), "/")
+}
+
 func trimTrailingSlash(path string) string {
 	if path == "/" {
 		return path
@@ -32,10 +32,13 @@ type request struct {
 	body     string
 }
 
-// Handler returns the raw query
+// Handler returns the encoded slots, followed by the raw query if there is one
 func handler(route string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(r.URL.RawQuery))
+		w.Write([]byte(router.Slots(r).Values().Encode()))
+		if r.URL.RawQuery != "" {
+			w.Write([]byte("?" + r.URL.RawQuery))
+		}
 	})
 }
 
@@ -158,12 +161,13 @@ func TestQueryPriority(t *testing.T) {
 			{method: "GET", route: "/users/:id.:format?"},
 			{method: "GET", route: "/posts/:post_id/comments/:id.:format?"},
 		},
+		// Slots are kept separate from the query string
 		requests: []*request{
-			{method: "GET", path: "/?id=10", status: 200, body: "id=10"},
-			{method: "GET", path: `/users/10?id=20&format=bin&other=true`, status: 200, body: "format=bin&id=10&other=true"},
-			{method: "GET", path: `/users/10.json?id=20&format=bin&other=true`, status: 200, body: "format=json&id=10&other=true"},
-			{method: "GET", path: `/posts/1/comments/2?post_id=10&id=20&other=true`, status: 200, body: "id=2&other=true&post_id=1"},
-			{method: "GET", path: `/posts/1/comments/2.json?format=bin&post_id=10&id=20&other=true`, status: 200, body: "format=json&id=2&other=true&post_id=1"},
+			{method: "GET", path: "/?id=10", status: 200, body: "?id=10"},
+			{method: "GET", path: `/users/10?id=20&format=bin&other=true`, status: 200, body: "id=10?id=20&format=bin&other=true"},
+			{method: "GET", path: `/users/10.json?id=20&format=bin&other=true`, status: 200, body: "format=json&id=10?id=20&format=bin&other=true"},
+			{method: "GET", path: `/posts/1/comments/2?post_id=10&id=20&other=true`, status: 200, body: "id=2&post_id=1?post_id=10&id=20&other=true"},
+			{method: "GET", path: `/posts/1/comments/2.json?format=bin&post_id=10&id=20&other=true`, status: 200, body: "format=json&id=2&post_id=1?format=bin&post_id=10&id=20&other=true"},
 		},
 	})
 }
@@ -235,3 +239,156 @@ func TestAdd(t *testing.T) {
 	is.NoErr(err)
 	is.Equal("id=10", string(body))
 }
+
+func TestHeadFromGet(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	is.NoErr(router.Get("/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello"))
+	})))
+	req := httptest.NewRequest(http.MethodHead, "/users/10", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	res := rec.Result()
+	is.Equal(200, res.StatusCode)
+	is.Equal("text/plain", res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.Equal("", string(body))
+}
+
+func TestHeadFromGetFlush(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	is.NoErr(router.Get("/events", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		is.True(ok)
+		w.Write([]byte("data: hello\n\n"))
+		flusher.Flush()
+	})))
+	req := httptest.NewRequest(http.MethodHead, "/events", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	res := rec.Result()
+	is.Equal(200, res.StatusCode)
+	is.True(rec.Flushed)
+	body, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.Equal("", string(body))
+}
+
+func TestOptions(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	is.NoErr(router.Get("/users/:id", handler("/users/:id")))
+	is.NoErr(router.Patch("/users/:id", handler("/users/:id")))
+	is.NoErr(router.Delete("/users/:id", handler("/users/:id")))
+	is.NoErr(router.Post("/users", handler("/users")))
+	req := httptest.NewRequest(http.MethodOptions, "/users/10", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	res := rec.Result()
+	is.Equal(204, res.StatusCode)
+	is.Equal("GET, HEAD, PATCH, DELETE, OPTIONS", res.Header.Get("Allow"))
+	req = httptest.NewRequest(http.MethodOptions, "/users", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	res = rec.Result()
+	is.Equal(204, res.StatusCode)
+	is.Equal("POST, OPTIONS", res.Header.Get("Allow"))
+	// Unknown paths fall through
+	req = httptest.NewRequest(http.MethodOptions, "/posts", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	res = rec.Result()
+	is.Equal(404, res.StatusCode)
+	is.Equal("", res.Header.Get("Allow"))
+}
+
+func TestExplicitOptions(t *testing.T) {
+	ok(t, &test{
+		routes: []*route{
+			{method: "GET", route: "/users/:id"},
+			{method: "OPTIONS", route: "/users/:id"},
+		},
+		requests: []*request{
+			{method: "OPTIONS", path: "/users/10", status: 200, body: "id=10"},
+		},
+	})
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	is.NoErr(router.Get("/users/:id", handler("/users/:id")))
+	is.NoErr(router.Put("/users/:id", handler("/users/:id")))
+	req := httptest.NewRequest(http.MethodPost, "/users/10/", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	res := rec.Result()
+	is.Equal(405, res.StatusCode)
+	is.Equal("GET, HEAD, PUT, OPTIONS", res.Header.Get("Allow"))
+	body, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.Equal("Method Not Allowed\n", string(body))
+	// Unknown paths fall through
+	req = httptest.NewRequest(http.MethodPost, "/posts/10", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	res = rec.Result()
+	is.Equal(404, res.StatusCode)
+	is.Equal("", res.Header.Get("Allow"))
+}
+
+func TestTrackRoute(t *testing.T) {
+	is := is.New(t)
+	rt := router.New()
+	is.NoErr(rt.Get("/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(router.Route(r)))
+	})))
+	tests := []struct {
+		path  string
+		route string
+	}{
+		{"/users/10", "/users/:id"},
+		{"/users/10/", "/users/:id"},
+		{"/posts/10", ""},
+	}
+	for _, test := range tests {
+		req := router.Track(httptest.NewRequest(http.MethodGet, test.path, nil))
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, req)
+		is.Equal(test.route, router.Route(req))
+		if test.route != "" {
+			is.Equal(test.route, rec.Body.String())
+		}
+	}
+	// Untracked requests don't have a route
+	req := httptest.NewRequest(http.MethodGet, "/users/10", nil)
+	rt.ServeHTTP(httptest.NewRecorder(), req)
+	is.Equal("", router.Route(req))
+}
+
+func TestConstraints(t *testing.T) {
+	ok(t, &test{
+		routes: []*route{
+			{method: "GET", route: "/users/new"},
+			{method: "GET", route: "/users/:id<int>"},
+			{method: "GET", route: "/users/:id<int>/edit"},
+			{method: "GET", route: "/posts/:slug<[A-Z]+>"},
+			{method: "GET", route: "/docs/:id<uuid>.:format<json>?"},
+			{method: "GET", route: "/bad/:id<[0-9>", err: "radix: invalid constraint \"[0-9\" in slot \"id\". error parsing regexp: missing closing ]: `[0-9)$`"},
+		},
+		requests: []*request{
+			{method: "GET", path: "/users/new", status: 200},
+			{method: "GET", path: "/users/10", status: 200, body: "id=10"},
+			{method: "GET", path: "/users/10/edit", status: 200, body: "id=10"},
+			{method: "GET", path: "/users/alice", status: 404, body: "404 page not found\n"},
+			{method: "GET", path: "/posts/ABC", status: 200, body: "slug=ABC"},
+			{method: "GET", path: "/posts/abc", status: 404, body: "404 page not found\n"},
+			{method: "GET", path: "/docs/123e4567-e89b-12d3-a456-426614174000.json", status: 200, body: "format=json&id=123e4567-e89b-12d3-a456-426614174000"},
+			{method: "GET", path: "/docs/123e4567.json", status: 404, body: "404 page not found\n"},
+		},
+	})
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/livebud/bud/package/router/lex"
+)
+
+// URL builds the path of a named route, filling in its slots with params.
+// Required slots must be provided. Optional and wildcard slots can be left
+// out, in which case the path ends before them.
+func (rt *Router) URL(name string, params map[string]string) (string, error) {
+	route, ok := rt.names[name]
+	if !ok {
+		return "", fmt.Errorf("router: no route named %q", name)
+	}
+	path, err := fill(route, params)
+	if err != nil {
+		return "", fmt.Errorf("router: unable to build the URL for %q. %w", name, err)
+	}
+	return path, nil
+}
+
+// fill in the route's slots with params
+func fill(route string, params map[string]string) (string, error) {
+	seen := map[string]bool{}
+	var tokens lex.Tokens
+	var missing []string
+	lexer := lex.NewCaseSensitive(route)
+	for {
+		token := lexer.Next()
+		switch token.Type {
+		case lex.ErrorToken:
+			return "", fmt.Errorf("%s", token.Value)
+		case lex.EndToken:
+			if len(missing) > 0 {
+				return "", fmt.Errorf("missing a value for %s", strings.Join(missing, ", "))
+			}
+			if err := unknown(params, seen); err != nil {
+				return "", err
+			}
+			path := new(strings.Builder)
+			for _, token := range tokens {
+				path.WriteString(token.Value)
+			}
+			return path.String(), nil
+		case lex.SlotToken, lex.QuestionToken, lex.StarToken:
+			slot := token.Slot()
+			seen[slot] = true
+			value := params[slot]
+			if value == "" {
+				if token.Type == lex.SlotToken {
+					missing = append(missing, fmt.Sprintf("%q", slot))
+					continue
+				}
+				// Optional and wildcard slots are at the end of the route, so stop
+				// the path where the router would stop matching it
+				tokens = stripTrail(tokens)
+				continue
+			}
+			tokens = append(tokens, lex.Token{Type: lex.SlotToken, Value: escape(token, value)})
+		default:
+			tokens = append(tokens, token)
+		}
+	}
+}
+
+// escape the slot's value. Wildcards may span multiple segments, so each
+// segment is escaped separately.
+func escape(token lex.Token, value string) string {
+	if token.Type != lex.StarToken {
+		return url.PathEscape(value)
+	}
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// stripTrail removes path tokens up to either a slot or a slash, the same way
+// the radix tree does before inserting the route without its optional slot.
+// e.g. /:id. => /:id
+//
+//	/a/b => /a
+func stripTrail(tokens lex.Tokens) lex.Tokens {
+	i := len(tokens) - 1
+loop:
+	for ; i >= 0; i-- {
+		switch tokens[i].Type {
+		case lex.SlotToken:
+			i++ // Include the slot
+			break loop
+		case lex.SlashToken:
+			break loop
+		}
+	}
+	if i <= 0 {
+		return tokens[:1]
+	}
+	return tokens[:i]
+}
+
+// unknown returns an error if there are params that aren't slots in the route
+func unknown(params map[string]string, seen map[string]bool) error {
+	var names []string
+	for name := range params {
+		if !seen[name] {
+			names = append(names, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown slots %s", strings.Join(names, ", "))
+}
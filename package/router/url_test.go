@@ -0,0 +1,82 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/router"
+)
+
+func TestURL(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	is.NoErr(router.Named("root", http.MethodGet, "/", handler("/")))
+	is.NoErr(router.Named("users", http.MethodGet, "/users/", handler("/users")))
+	is.NoErr(router.Named("user", http.MethodGet, "/users/:id.:format?", handler("/users/:id.:format?")))
+	is.NoErr(router.Named("comment", http.MethodGet, "/posts/:post_id/comments/:id", handler("/posts/:post_id/comments/:id")))
+	is.NoErr(router.Named("post", http.MethodGet, "/posts/:slug?", handler("/posts/:slug?")))
+	is.NoErr(router.Named("doc", http.MethodGet, "/docs/:id<int>.:format<json|xml>?", handler("/docs/:id<int>.:format<json|xml>?")))
+	is.NoErr(router.Named("file", http.MethodGet, "/files/:path*", handler("/files/:path*")))
+	tests := []struct {
+		name   string
+		params map[string]string
+		url    string
+		err    string
+	}{
+		{name: "root", url: "/"},
+		{name: "users", url: "/users"},
+		{name: "user", params: map[string]string{"id": "10"}, url: "/users/10"},
+		{name: "user", params: map[string]string{"id": "10", "format": "json"}, url: "/users/10.json"},
+		{name: "user", params: map[string]string{"id": "a b/c"}, url: "/users/a%20b%2Fc"},
+		{name: "user", err: `router: unable to build the URL for "user". missing a value for "id"`},
+		{name: "user", params: map[string]string{"format": "json"}, err: `router: unable to build the URL for "user". missing a value for "id"`},
+		{name: "user", params: map[string]string{"id": "10", "slug": "x"}, err: `router: unable to build the URL for "user". unknown slots "slug"`},
+		{name: "comment", params: map[string]string{"post_id": "1", "id": "2"}, url: "/posts/1/comments/2"},
+		{name: "comment", err: `router: unable to build the URL for "comment". missing a value for "post_id", "id"`},
+		{name: "post", url: "/posts"},
+		{name: "post", params: map[string]string{"slug": "hello"}, url: "/posts/hello"},
+		{name: "doc", params: map[string]string{"id": "10"}, url: "/docs/10"},
+		{name: "doc", params: map[string]string{"id": "10", "format": "xml"}, url: "/docs/10.xml"},
+		{name: "file", url: "/files"},
+		{name: "file", params: map[string]string{"path": "a b/c.txt"}, url: "/files/a%20b/c.txt"},
+		{name: "missing", err: `router: no route named "missing"`},
+	}
+	for _, test := range tests {
+		url, err := router.URL(test.name, test.params)
+		if test.err != "" {
+			is.True(err != nil)
+			is.Equal(test.err, err.Error())
+			continue
+		}
+		is.NoErr(err)
+		is.Equal(test.url, url)
+		// The URL should route back to the named route. Escaped slashes are
+		// decoded before routing, so they don't route back.
+		if strings.Contains(url, "%2F") {
+			continue
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		is.Equal(200, rec.Code)
+	}
+}
+
+func TestNamedDuplicate(t *testing.T) {
+	is := is.New(t)
+	router := router.New()
+	is.NoErr(router.Named("user", http.MethodGet, "/users/:id", handler("/users/:id")))
+	err := router.Named("user", http.MethodPatch, "/users/:id", handler("/users/:id"))
+	is.True(err != nil)
+	is.Equal(`router: "user" is already the name of "/users/:id"`, err.Error())
+	err = router.Named("", http.MethodGet, "/posts", handler("/posts"))
+	is.True(err != nil)
+	is.Equal(`router: route "/posts" must have a name`, err.Error())
+	err = router.Named("posts", "GOT", "/posts", handler("/posts"))
+	is.True(err != nil)
+	is.Equal(`router: "GOT" is not a valid HTTP method`, err.Error())
+	_, err = router.URL("posts", nil)
+	is.True(err != nil)
+}
@@ -0,0 +1,207 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Option to configure the session cookie
+type Option = func(o *option)
+
+type option struct {
+	Name   string
+	MaxAge time.Duration
+	Secure bool
+}
+
+// WithName sets the name of the session cookie. Defaults to "session".
+func WithName(name string) Option {
+	return func(o *option) {
+		o.Name = name
+	}
+}
+
+// WithMaxAge sets how long the session lasts. By default, the session ends when
+// the browser is closed.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(o *option) {
+		o.MaxAge = maxAge
+	}
+}
+
+// WithSecure only sends the session cookie over HTTPS
+func WithSecure(secure bool) Option {
+	return func(o *option) {
+		o.Secure = secure
+	}
+}
+
+func newOption(options []Option) *option {
+	opt := &option{
+		Name: "session",
+	}
+	for _, option := range options {
+		option(opt)
+	}
+	return opt
+}
+
+// cookie creates the session cookie with the value
+func (o *option) cookie(value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     o.Name,
+		Value:    value,
+		Path:     "/",
+		Secure:   o.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if o.MaxAge > 0 {
+		cookie.MaxAge = int(o.MaxAge.Seconds())
+	}
+	return cookie
+}
+
+// expire the session cookie
+func (o *option) expire() *http.Cookie {
+	cookie := o.cookie("")
+	cookie.MaxAge = -1
+	return cookie
+}
+
+// MinSecretSize is the minimum number of bytes in the cookie store's secret
+const MinSecretSize = 32
+
+// maxCookieSize is the largest cookie that browsers are required to accept
+const maxCookieSize = 4096
+
+// GenerateSecret generates a random secret for the cookie store. Sessions
+// signed with a generated secret don't survive restarts.
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, MinSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// NewCookieStore stores the session in a cookie that's encrypted and signed
+// with the secret, so it can't be read or changed by the browser.
+func NewCookieStore(secret []byte, options ...Option) (*CookieStore, error) {
+	if len(secret) < MinSecretSize {
+		return nil, fmt.Errorf("session: secret must be at least %d bytes, got %d", MinSecretSize, len(secret))
+	}
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieStore{aead, newOption(options), time.Now}, nil
+}
+
+// CookieStore keeps the whole session in an encrypted cookie
+type CookieStore struct {
+	aead   cipher.AEAD
+	option *option
+	now    func() time.Time
+}
+
+var _ Store = (*CookieStore)(nil)
+
+// envelope is encrypted into the cookie
+type envelope struct {
+	Data    *Data `json:"data"`
+	Expires int64 `json:"expires,omitempty"`
+}
+
+// Load the session from the cookie. Cookies that have been tampered with,
+// were signed with another secret or have expired start a new session.
+func (c *CookieStore) Load(r *http.Request) (*Data, error) {
+	cookie, err := r.Cookie(c.option.Name)
+	if err != nil {
+		return new(Data), nil
+	}
+	env, err := c.decode(cookie.Value)
+	if err != nil {
+		return new(Data), nil
+	}
+	if env.Expires > 0 && c.now().Unix() > env.Expires {
+		return new(Data), nil
+	}
+	return env.Data, nil
+}
+
+// Save the session into the cookie, removing the cookie if the session is
+// empty
+func (c *CookieStore) Save(w http.ResponseWriter, r *http.Request, data *Data) error {
+	if data.Empty() {
+		http.SetCookie(w, c.option.expire())
+		return nil
+	}
+	env := &envelope{Data: data}
+	if c.option.MaxAge > 0 {
+		env.Expires = c.now().Add(c.option.MaxAge).Unix()
+	}
+	value, err := c.encode(env)
+	if err != nil {
+		return err
+	}
+	cookie := c.option.cookie(value)
+	if size := len(cookie.String()); size > maxCookieSize {
+		return fmt.Errorf("session: cookie is %d bytes, which is larger than the %d bytes browsers accept", size, maxCookieSize)
+	}
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+func (c *CookieStore) encode(env *envelope) (string, error) {
+	plaintext, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	// The cookie name is authenticated, so a value can't be moved to another
+	// cookie
+	sealed := c.aead.Seal(nonce, nonce, plaintext, []byte(c.option.Name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+var errInvalidCookie = errors.New("session: invalid cookie")
+
+func (c *CookieStore) decode(value string) (*envelope, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCookie
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errInvalidCookie
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(c.option.Name))
+	if err != nil {
+		return nil, errInvalidCookie
+	}
+	env := new(envelope)
+	if err := json.Unmarshal(plaintext, env); err != nil {
+		return nil, errInvalidCookie
+	}
+	if env.Data == nil {
+		env.Data = new(Data)
+	}
+	return env, nil
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+)
+
+// NewMemoryStore stores sessions in memory with only the session ID in the
+// cookie. Sessions are lost when the process exits, so it's meant for tests.
+func NewMemoryStore(options ...Option) *MemoryStore {
+	return &MemoryStore{
+		option:   newOption(options),
+		sessions: map[string]*Data{},
+	}
+}
+
+// MemoryStore keeps sessions in memory
+type MemoryStore struct {
+	option   *option
+	mu       sync.Mutex
+	sessions map[string]*Data
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// Load the session by the ID in the cookie
+func (m *MemoryStore) Load(r *http.Request) (*Data, error) {
+	cookie, err := r.Cookie(m.option.Name)
+	if err != nil {
+		return new(Data), nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.sessions[cookie.Value]
+	if !ok {
+		return new(Data), nil
+	}
+	return copyData(data), nil
+}
+
+// Save the session under the ID in the cookie, creating a new ID if the
+// request doesn't have one
+func (m *MemoryStore) Save(w http.ResponseWriter, r *http.Request, data *Data) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := ""
+	if cookie, err := r.Cookie(m.option.Name); err == nil {
+		if _, ok := m.sessions[cookie.Value]; ok {
+			id = cookie.Value
+		}
+	}
+	if data.Empty() {
+		if id != "" {
+			delete(m.sessions, id)
+			http.SetCookie(w, m.option.expire())
+		}
+		return nil
+	}
+	if id == "" {
+		// Don't trust IDs that weren't created by the store
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		id = hex.EncodeToString(buf)
+		http.SetCookie(w, m.option.cookie(id))
+	}
+	m.sessions[id] = copyData(data)
+	return nil
+}
+
+// Len returns the number of sessions in the store
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// copyData so sessions in memory aren't changed by the requests using them
+func copyData(data *Data) *Data {
+	copied := &Data{
+		Flashes: append([]*Flash{}, data.Flashes...),
+	}
+	if len(data.Values) > 0 {
+		copied.Values = make(map[string]string, len(data.Values))
+		for key, value := range data.Values {
+			copied.Values[key] = value
+		}
+	}
+	return copied
+}
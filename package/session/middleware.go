@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/livebud/bud/package/log"
+)
+
+// New session middleware that saves changes to the session in the store
+func New(log log.Log, store Store) *Middleware {
+	return &Middleware{log, store}
+}
+
+// Middleware loads sessions from the store and saves them before the response
+// is written. Changes made after the response starts aren't saved.
+type Middleware struct {
+	log   log.Log
+	store Store
+}
+
+type contextKey struct{}
+
+// Middleware makes the session available to Load
+func (m *Middleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := &state{store: m.store}
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, state))
+		save := func() {
+			if err := state.save(w, r); err != nil {
+				m.log.Field("error", err).Error("session: unable to save")
+			}
+		}
+		sw := httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(writeHeader httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					save()
+					writeHeader(code)
+				}
+			},
+			Write: func(write httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(p []byte) (int, error) {
+					save()
+					return write(p)
+				}
+			},
+			ReadFrom: func(readFrom httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					save()
+					return readFrom(src)
+				}
+			},
+			Flush: func(flush httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+				return func() {
+					save()
+					flush()
+				}
+			},
+		})
+		next.ServeHTTP(sw, r)
+		// Save the session if nothing was written
+		save()
+	})
+}
+
+// state of the session within a request. The session is only loaded from the
+// store when it's needed and saved once.
+type state struct {
+	mu      sync.Mutex
+	store   Store
+	session *Session
+	err     error
+	loaded  bool
+	saved   bool
+}
+
+func (s *state) load(r *http.Request) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		return s.session, s.err
+	}
+	s.loaded = true
+	data, err := s.store.Load(r)
+	if err != nil {
+		s.err = err
+		return nil, err
+	}
+	s.session = &Session{
+		values:  data.Values,
+		flashes: data.Flashes,
+	}
+	return s.session, nil
+}
+
+func (s *state) save(w http.ResponseWriter, r *http.Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saved {
+		return nil
+	}
+	s.saved = true
+	// Sessions that weren't loaded haven't changed
+	if s.session == nil {
+		return nil
+	}
+	data, changed := s.session.data()
+	if !changed {
+		return nil
+	}
+	return s.store.Save(w, r, data)
+}
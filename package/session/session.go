@@ -0,0 +1,121 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Store loads and saves the session data of a request
+type Store interface {
+	Load(r *http.Request) (*Data, error)
+	Save(w http.ResponseWriter, r *http.Request, data *Data) error
+}
+
+// Data is the part of the session that's kept between requests
+type Data struct {
+	Values  map[string]string `json:"values,omitempty"`
+	Flashes []*Flash          `json:"flashes,omitempty"`
+}
+
+// Empty returns true if there's nothing in the session
+func (d *Data) Empty() bool {
+	return len(d.Values) == 0 && len(d.Flashes) == 0
+}
+
+// Flash is a message for the next page that's rendered, like "Post created"
+type Flash struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ErrNoMiddleware occurs when the session is loaded outside of the middleware
+var ErrNoMiddleware = errors.New("session: request wasn't handled by the session middleware")
+
+// Load the session of the request. The session is read from the store the
+// first time it's loaded and saved before the response is written.
+func Load(r *http.Request) (*Session, error) {
+	state, ok := r.Context().Value(contextKey{}).(*state)
+	if !ok {
+		return nil, ErrNoMiddleware
+	}
+	return state.load(r)
+}
+
+// Session of the current user
+type Session struct {
+	mu      sync.Mutex
+	values  map[string]string
+	flashes []*Flash // flashes that haven't been shown yet
+	changed bool
+}
+
+// Get a value from the session
+func (s *Session) Get(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// Set a value in the session
+func (s *Session) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = map[string]string{}
+	}
+	s.values[key] = value
+	s.changed = true
+}
+
+// Delete a value from the session
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; !ok {
+		return
+	}
+	delete(s.values, key)
+	s.changed = true
+}
+
+// Clear all the values and flashes from the session, like when logging out
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = nil
+	s.flashes = nil
+	s.changed = true
+}
+
+// Flash a message on the next page that's rendered
+func (s *Session) Flash(kind, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flashes = append(s.flashes, &Flash{Type: kind, Message: message})
+	s.changed = true
+}
+
+// Flashes returns the flash messages that haven't been shown yet and removes
+// them from the session. Views are passed these flashes automatically in their
+// "flash" prop.
+func (s *Session) Flashes() []*Flash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes := s.flashes
+	if len(flashes) > 0 {
+		s.flashes = nil
+		s.changed = true
+	}
+	return flashes
+}
+
+// data returns what needs to be saved and whether it changed
+func (s *Session) data() (*Data, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &Data{
+		Values:  s.values,
+		Flashes: s.flashes,
+	}, s.changed
+}
@@ -0,0 +1,222 @@
+package session_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/log"
+	"github.com/livebud/bud/package/session"
+)
+
+var secret = []byte(strings.Repeat("s", session.MinSecretSize))
+
+// client keeps the cookies between requests
+type client struct {
+	t       testing.TB
+	handler http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func newClient(t testing.TB, store session.Store, handler http.HandlerFunc) *client {
+	return &client{
+		t:       t,
+		handler: session.New(log.Discard, store).Middleware(handler),
+		cookies: map[string]*http.Cookie{},
+	}
+}
+
+func (c *client) Get(url string) (*http.Response, string) {
+	c.t.Helper()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	res := rec.Result()
+	for _, cookie := range res.Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(c.cookies, cookie.Name)
+			continue
+		}
+		c.cookies[cookie.Name] = cookie
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return res, string(body)
+}
+
+// handler changes the session depending on the path
+func handler(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.Load(r)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	switch r.URL.Path {
+	case "/login":
+		sess.Set("user", r.URL.Query().Get("user"))
+		sess.Flash("notice", "Welcome")
+	case "/logout":
+		sess.Clear()
+	case "/flash":
+		for _, flash := range sess.Flashes() {
+			w.Write([]byte(flash.Type + ": " + flash.Message + "\n"))
+		}
+	}
+	w.Write([]byte("user=" + sess.Get("user")))
+}
+
+func testStore(t *testing.T, store session.Store) {
+	is := is.New(t)
+	c := newClient(t, store, handler)
+	res, body := c.Get("/")
+	is.Equal("user=", body)
+	// Unchanged sessions aren't saved
+	is.Equal(0, len(res.Cookies()))
+	res, body = c.Get("/login?user=alice")
+	is.Equal("user=alice", body)
+	is.Equal(1, len(res.Cookies()))
+	// Flashes are kept until they're read
+	_, body = c.Get("/")
+	is.Equal("user=alice", body)
+	_, body = c.Get("/flash")
+	is.Equal("notice: Welcome\nuser=alice", body)
+	_, body = c.Get("/flash")
+	is.Equal("user=alice", body)
+	_, body = c.Get("/logout")
+	is.Equal("user=", body)
+	is.Equal(0, len(c.cookies))
+	_, body = c.Get("/")
+	is.Equal("user=", body)
+}
+
+func TestCookieStore(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	testStore(t, store)
+}
+
+func TestMemoryStore(t *testing.T) {
+	is := is.New(t)
+	store := session.NewMemoryStore()
+	testStore(t, store)
+	is.Equal(0, store.Len())
+}
+
+func TestCookieTampered(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	c := newClient(t, store, handler)
+	c.Get("/login?user=alice")
+	cookie := c.cookies["session"]
+	is.True(cookie != nil)
+	// The value is encrypted
+	is.True(!strings.Contains(cookie.Value, "alice"))
+	// Changing the cookie starts a new session
+	value := []byte(cookie.Value)
+	if value[10] == 'a' {
+		value[10] = 'b'
+	} else {
+		value[10] = 'a'
+	}
+	c.cookies["session"] = &http.Cookie{Name: "session", Value: string(value)}
+	_, body := c.Get("/")
+	is.Equal("user=", body)
+	// Cookies from another secret start a new session
+	other, err := session.NewCookieStore([]byte(strings.Repeat("o", session.MinSecretSize)))
+	is.NoErr(err)
+	c.cookies["session"] = cookie
+	_, body = c.Get("/")
+	is.Equal("user=alice", body)
+	c.handler = session.New(log.Discard, other).Middleware(http.HandlerFunc(handler))
+	_, body = c.Get("/")
+	is.Equal("user=", body)
+}
+
+func TestCookieOptions(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret,
+		session.WithName("sid"),
+		session.WithMaxAge(time.Hour),
+		session.WithSecure(true),
+	)
+	is.NoErr(err)
+	c := newClient(t, store, handler)
+	res, _ := c.Get("/login?user=alice")
+	cookies := res.Cookies()
+	is.Equal(1, len(cookies))
+	is.Equal("sid", cookies[0].Name)
+	is.Equal(3600, cookies[0].MaxAge)
+	is.True(cookies[0].Secure)
+	is.True(cookies[0].HttpOnly)
+	is.Equal(http.SameSiteLaxMode, cookies[0].SameSite)
+	_, body := c.Get("/")
+	is.Equal("user=alice", body)
+}
+
+func TestCookieTooLarge(t *testing.T) {
+	is := is.New(t)
+	store, err := session.NewCookieStore(secret)
+	is.NoErr(err)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	err = store.Save(rec, req, &session.Data{
+		Values: map[string]string{"big": strings.Repeat("a", 5000)},
+	})
+	is.True(err != nil)
+	is.In(err.Error(), "larger than the 4096 bytes browsers accept")
+}
+
+func TestShortSecret(t *testing.T) {
+	is := is.New(t)
+	_, err := session.NewCookieStore([]byte("short"))
+	is.True(err != nil)
+	is.Equal("session: secret must be at least 32 bytes, got 5", err.Error())
+	generated, err := session.GenerateSecret()
+	is.NoErr(err)
+	_, err = session.NewCookieStore(generated)
+	is.NoErr(err)
+}
+
+func TestSaveBeforeWrite(t *testing.T) {
+	is := is.New(t)
+	store := session.NewMemoryStore()
+	c := newClient(t, store, func(w http.ResponseWriter, r *http.Request) {
+		sess, err := session.Load(r)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		sess.Set("before", "1")
+		w.WriteHeader(http.StatusCreated)
+		// Changes after the response starts aren't saved
+		sess.Set("after", "1")
+	})
+	res, _ := c.Get("/")
+	is.Equal(201, res.StatusCode)
+	is.Equal(1, store.Len())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(c.cookies["session"])
+	sess, err := store.Load(req)
+	is.NoErr(err)
+	is.Equal(map[string]string{"before": "1"}, sess.Values)
+}
+
+func TestNoMiddleware(t *testing.T) {
+	is := is.New(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	sess, err := session.Load(req)
+	is.True(err != nil)
+	is.True(sess == nil)
+	is.Equal(session.ErrNoMiddleware, err)
+}
@@ -0,0 +1,38 @@
+package upload
+
+import (
+	"errors"
+	"mime/multipart"
+)
+
+// New file from a multipart file header
+func New(header *multipart.FileHeader) *File {
+	return &File{
+		Filename:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		header:      header,
+	}
+}
+
+// File that was uploaded in a multipart/form-data request. Use this type in
+// your action's params to accept file uploads.
+type File struct {
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	header      *multipart.FileHeader
+}
+
+// ErrNoFile is returned when opening a file that wasn't uploaded, like a file
+// decoded from a JSON body
+var ErrNoFile = errors.New("upload: no uploaded file to open")
+
+// Open the uploaded file for reading. Uploads are kept in memory or in a
+// temporary file until the request finishes.
+func (f *File) Open() (multipart.File, error) {
+	if f.header == nil {
+		return nil, ErrNoFile
+	}
+	return f.header.Open()
+}
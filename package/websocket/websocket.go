@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/websocket"
+)
+
+// Conn is an upgraded WebSocket connection. Use this type in your action's
+// params to accept WebSocket connections.
+type Conn struct {
+	ws *websocket.Conn
+}
+
+// Request that was upgraded into this connection
+func (c *Conn) Request() *http.Request {
+	return c.ws.Request()
+}
+
+// Send the value as a JSON-encoded text message
+func (c *Conn) Send(v interface{}) error {
+	return websocket.JSON.Send(c.ws, v)
+}
+
+// Receive the next message, decoding the JSON into v
+func (c *Conn) Receive(v interface{}) error {
+	return websocket.JSON.Receive(c.ws, v)
+}
+
+// SendText sends a raw text message
+func (c *Conn) SendText(message string) error {
+	return websocket.Message.Send(c.ws, message)
+}
+
+// ReceiveText receives the next raw text message
+func (c *Conn) ReceiveText() (message string, err error) {
+	err = websocket.Message.Receive(c.ws, &message)
+	return message, err
+}
+
+// Close the connection
+func (c *Conn) Close() error {
+	return c.ws.Close()
+}
+
+// closeInternalError is the close status for a server that hit an unexpected
+// condition, as described in RFC 6455
+const closeInternalError = 1011
+
+// Handler upgrades the request into a WebSocket connection and calls fn with
+// the connection. The connection is closed once fn returns. If fn returns an
+// error, the connection is closed with the 1011 internal error status.
+func Handler(fn func(conn *Conn) error) http.Handler {
+	server := websocket.Server{
+		Handshake: sameOrigin,
+		Handler: func(ws *websocket.Conn) {
+			// There's no response left to write the error to after the upgrade,
+			// so the error is sent as the close status instead. Clients that
+			// disconnect first aren't errors.
+			if err := fn(&Conn{ws}); err != nil && !errors.Is(err, io.EOF) {
+				ws.WriteClose(closeInternalError)
+			}
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsUpgrade(r) {
+			http.Error(w, "websocket: expected a websocket upgrade request", http.StatusBadRequest)
+			return
+		}
+		server.ServeHTTP(w, r)
+	})
+}
+
+// IsUpgrade returns true if the request is asking to upgrade to a WebSocket
+func IsUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		headerContains(r.Header, "Connection", "upgrade")
+}
+
+func headerContains(header http.Header, key, value string) bool {
+	for _, field := range header.Values(key) {
+		for _, token := range strings.Split(field, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameOrigin rejects browser connections from other origins. Clients outside
+// the browser don't send an Origin header, so they're allowed through.
+func sameOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("websocket: invalid origin %q. %w", origin, err)
+	}
+	if u.Host != r.Host {
+		return fmt.Errorf("websocket: origin %q doesn't match host %q", origin, r.Host)
+	}
+	config.Origin = u
+	return nil
+}
@@ -0,0 +1,127 @@
+package websocket_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livebud/bud/internal/is"
+	"github.com/livebud/bud/package/websocket"
+	xwebsocket "golang.org/x/net/websocket"
+)
+
+type message struct {
+	Text string `json:"text"`
+}
+
+func echo(conn *websocket.Conn) error {
+	for {
+		var msg message
+		if err := conn.Receive(&msg); err != nil {
+			return err
+		}
+		msg.Text = strings.ToUpper(msg.Text)
+		if err := conn.Send(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+func dial(t testing.TB, server *httptest.Server, origin string) (*xwebsocket.Conn, error) {
+	t.Helper()
+	config, err := xwebsocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http"), origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return xwebsocket.DialConfig(config)
+}
+
+func TestEcho(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(websocket.Handler(echo))
+	defer server.Close()
+	ws, err := dial(t, server, server.URL)
+	is.NoErr(err)
+	defer ws.Close()
+	is.NoErr(xwebsocket.JSON.Send(ws, &message{"hello"}))
+	var msg message
+	is.NoErr(xwebsocket.JSON.Receive(ws, &msg))
+	is.Equal(msg.Text, "HELLO")
+	is.NoErr(xwebsocket.JSON.Send(ws, &message{"world"}))
+	is.NoErr(xwebsocket.JSON.Receive(ws, &msg))
+	is.Equal(msg.Text, "WORLD")
+}
+
+func TestText(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) error {
+		message, err := conn.ReceiveText()
+		if err != nil {
+			return err
+		}
+		return conn.SendText(message + " " + conn.Request().URL.Path)
+	}))
+	defer server.Close()
+	ws, err := dial(t, server, server.URL)
+	is.NoErr(err)
+	defer ws.Close()
+	is.NoErr(xwebsocket.Message.Send(ws, "hi"))
+	var message string
+	is.NoErr(xwebsocket.Message.Receive(ws, &message))
+	is.Equal(message, "hi /")
+}
+
+func TestCrossOrigin(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(websocket.Handler(echo))
+	defer server.Close()
+	_, err := dial(t, server, "http://evil.com")
+	is.True(err != nil)
+	is.In(err.Error(), "bad status")
+}
+
+func TestNotUpgrade(t *testing.T) {
+	is := is.New(t)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	websocket.Handler(echo).ServeHTTP(rec, req)
+	is.Equal(rec.Code, http.StatusBadRequest)
+}
+
+// recorder records the bytes read from the connection
+type recorder struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.Conn.Read(p)
+	r.buf.Write(p[:n])
+	return n, err
+}
+
+func TestError(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) error {
+		return errors.New("unable to join the room")
+	}))
+	defer server.Close()
+	config, err := xwebsocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http"), server.URL)
+	is.NoErr(err)
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	is.NoErr(err)
+	rec := &recorder{Conn: conn}
+	ws, err := xwebsocket.NewClient(config, rec)
+	is.NoErr(err)
+	defer ws.Close()
+	var message string
+	err = xwebsocket.Message.Receive(ws, &message)
+	is.Equal(err, io.EOF)
+	// The close frame has the 1011 internal error status
+	is.True(bytes.HasSuffix(rec.buf.Bytes(), []byte{0x88, 0x02, 0x03, 0xf3}))
+}
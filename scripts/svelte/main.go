@@ -14,6 +14,7 @@ import (
 	"github.com/livebud/bud/package/hot"
 	"github.com/livebud/bud/package/log"
 	"github.com/livebud/bud/package/log/console"
+	"github.com/livebud/bud/package/middleware/cors"
 	"github.com/livebud/bud/package/router"
 	"github.com/livebud/bud/package/transpiler"
 	"github.com/livebud/bud/package/viewer"
@@ -43,7 +44,7 @@ func run() error {
 
 func hotServer(log log.Log, ps pubsub.Client) error {
 	router := router.New()
-	router.Get("/bud/hot/:path*", hot.New(log, ps))
+	router.Group("/bud/hot", cors.New()).Get("/:path*", hot.New(log, ps))
 	return http.ListenAndServe(":35729", router)
 }
 